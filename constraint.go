@@ -0,0 +1,98 @@
+package urlrouter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Constraint reports if the value of a named parameter is allowed to match a route
+type Constraint func(value string) bool
+
+// Constraints is a library of constraints that can be referenced by name in a route's
+// pattern, such as "/users/:id<int>"
+type Constraints map[string]Constraint
+
+// defaultConstraints are always available to a Router, unless replaced with the same name
+var defaultConstraints = Constraints{
+	"int": func(value string) bool {
+		value = strings.TrimPrefix(value, "-")
+		return value != "" && strings.Trim(value, "0123456789") == ""
+	},
+	"uuid": func(value string) bool {
+		if len(value) != 36 {
+			return false
+		}
+
+		for index, char := range value {
+			switch index {
+			case 8, 13, 18, 23:
+				if char != '-' {
+					return false
+				}
+			default:
+				if !strings.ContainsRune("0123456789abcdefABCDEF", char) {
+					return false
+				}
+			}
+		}
+
+		return true
+	},
+	"alpha": func(value string) bool {
+		return value != "" && strings.Trim(value, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") == ""
+	},
+	"alnum": func(value string) bool {
+		return value != "" && strings.Trim(value, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") == ""
+	},
+}
+
+// parseNamedParameter splits a named parameter's path into the name and the constraint.
+// Constraints are either a regular expression that must match the whole value, such as
+// ":id{[0-9]+}", or the name of a constraint in the library, such as ":id<int>". An invalid
+// regular expression or an unknown constraint is an ErrInvalidPattern error
+func parseNamedParameter(path string, constraints Constraints) (string, string, Constraint, error) {
+	name, constraint := splitNamedParameter(path)
+
+	switch {
+	case strings.HasPrefix(constraint, "{"):
+		regex, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", constraint[1:len(constraint)-1]))
+		if err != nil {
+			return "", "", nil, fmt.Errorf("%w: named parameter %q has an invalid regular expression: %s", ErrInvalidPattern, path, err)
+		}
+
+		return name, constraint, regex.MatchString, nil
+	case strings.HasPrefix(constraint, "<"):
+		constraintName := constraint[1 : len(constraint)-1]
+
+		constraintFunc, ok := constraints[constraintName]
+		if !ok {
+			constraintFunc, ok = defaultConstraints[constraintName]
+		}
+
+		if !ok {
+			return "", "", nil, fmt.Errorf("%w: named parameter %q uses an unknown constraint %q", ErrInvalidPattern, path, constraintName)
+		}
+
+		return name, constraint, constraintFunc, nil
+	default:
+		return name, "", nil, nil
+	}
+}
+
+// splitNamedParameter splits a named parameter's path into the name and the constraint,
+// without checking that the constraint is valid
+func splitNamedParameter(path string) (string, string) {
+	name := trimPaths(path)
+
+	switch {
+	case strings.HasSuffix(name, "}") && strings.Contains(name, "{"):
+		index := strings.Index(name, "{")
+		return name[:index], name[index:]
+	case strings.HasSuffix(name, ">") && strings.Contains(name, "<"):
+		index := strings.Index(name, "<")
+		return name[:index], name[index:]
+	default:
+		return name, ""
+	}
+}
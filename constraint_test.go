@@ -0,0 +1,73 @@
+package urlrouter
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestInternalFunction_defaultConstraints(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		constraint string
+		valid      []string
+		invalid    []string
+	}{
+		{constraint: "int", valid: []string{"0", "42", "-42"}, invalid: []string{"", "-", "4.2", "a1"}},
+		{constraint: "uuid", valid: []string{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", "6BA7B810-9DAD-11D1-80B4-00C04FD430C8"}, invalid: []string{"", "6ba7b8109dad11d180b400c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430cz"}},
+		{constraint: "alpha", valid: []string{"abc", "ABC"}, invalid: []string{"", "abc1", "a-b"}},
+		{constraint: "alnum", valid: []string{"abc", "abc123"}, invalid: []string{"", "abc-123"}},
+	}
+
+	for _, test := range tests {
+		t.Run("It validates the "+test.constraint+" constraint", func(t *testing.T) {
+			for _, value := range test.valid {
+				g.Expect(defaultConstraints[test.constraint](value)).To(BeTrue(), value)
+			}
+
+			for _, value := range test.invalid {
+				g.Expect(defaultConstraints[test.constraint](value)).To(BeFalse(), value)
+			}
+		})
+	}
+}
+
+func TestInternalFunction_parseNamedParameter(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("It parses a named parameter without a constraint", func(t *testing.T) {
+		name, constraint, constraintFunc, err := parseNamedParameter(":id", nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(name).To(Equal("id"))
+		g.Expect(constraint).To(BeEmpty())
+		g.Expect(constraintFunc).To(BeNil())
+	})
+
+	t.Run("It parses a regular expression that must match the whole value", func(t *testing.T) {
+		name, constraint, constraintFunc, err := parseNamedParameter(":id{[0-9]+}", nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(name).To(Equal("id"))
+		g.Expect(constraint).To(Equal("{[0-9]+}"))
+		g.Expect(constraintFunc("123")).To(BeTrue())
+		g.Expect(constraintFunc("123a")).To(BeFalse())
+	})
+
+	t.Run("It prefers the library's constraint over the defaults", func(t *testing.T) {
+		name, constraint, constraintFunc, err := parseNamedParameter(":id<int>", Constraints{"int": func(string) bool { return false }})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(name).To(Equal("id"))
+		g.Expect(constraint).To(Equal("<int>"))
+		g.Expect(constraintFunc("123")).To(BeFalse())
+	})
+
+	t.Run("It errors on an invalid regular expression", func(t *testing.T) {
+		_, _, _, err := parseNamedParameter(":id{[0-9+}", nil)
+		g.Expect(err).To(MatchError(ErrInvalidPattern))
+	})
+
+	t.Run("It errors on an unknown constraint", func(t *testing.T) {
+		_, _, _, err := parseNamedParameter(":id<even>", nil)
+		g.Expect(err).To(MatchError(ErrInvalidPattern))
+	})
+}
@@ -2,11 +2,13 @@ module github.com/DanLavine/urlrouter
 
 go 1.19
 
-require github.com/onsi/gomega v1.29.0
+require (
+	github.com/onsi/gomega v1.29.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/google/go-cmp v0.6.0 // indirect
 	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/text v0.13.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,158 @@
+package urlrouter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mountPathParameter is the catch all parameter that captures the path beneath a mounted handler.
+// It is removed from the named parameters before the mounted handler is called
+const mountPathParameter = "urlrouter_mount_path"
+
+// mountMethods are the methods a mounted handler is registered under
+var mountMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// Group registers routes beneath a shared prefix, with its own middleware stack. The prefix
+// can include named parameters, such as "/tenants/:tenant", that are passed to every route
+// in the group
+type Group struct {
+	router     *Router
+	host       string
+	prefix     string
+	middleware []Middleware
+}
+
+// Group creates a group of routes beneath the prefix
+func (router *Router) Group(prefix string) *Group {
+	return &Group{router: router, prefix: prefix}
+}
+
+// Mount serves every request for the prefix and the paths beneath it with the handler,
+// for all the standard HTTP methods. The prefix is stripped from the request's path before
+// calling the handler, and any named parameters in the prefix are set on the request
+func (router *Router) Mount(prefix string, handler http.Handler) {
+	router.Group(prefix).Mount("", handler)
+}
+
+// Group creates a nested group of routes beneath the group's prefix. The nested group starts
+// with a copy of the group's middleware
+func (group *Group) Group(prefix string) *Group {
+	return &Group{
+		router:     group.router,
+		host:       group.host,
+		prefix:     joinPaths(group.prefix, prefix),
+		middleware: append([]Middleware{}, group.middleware...),
+	}
+}
+
+// Use adds middleware to the group. Middleware only wraps the routes registered after it
+// was added, and runs in the order it was added
+func (group *Group) Use(middleware ...Middleware) {
+	group.middleware = append(group.middleware, middleware...)
+}
+
+// HandleFunc adds a new url handler beneath the group's prefix. The group's middleware runs
+// before any middleware passed as an option. See Router.HandleFunc
+func (group *Group) HandleFunc(method string, path string, handlerFunc http.HandlerFunc, options ...RouteOption) {
+	group.router.HandleFunc(method, joinPaths(group.prefix, path), handlerFunc, group.options(options)...)
+}
+
+// Add a new url handler beneath the group's prefix, returning an error instead of panicking.
+// See Router.Add
+func (group *Group) Add(method string, path string, handler http.Handler, options ...RouteOption) error {
+	return group.router.Add(method, joinPaths(group.prefix, path), handler, group.options(options)...)
+}
+
+// Remove the url handler registered for the method and the path beneath the group's prefix,
+// for the group's host. See Router.Remove
+func (group *Group) Remove(method string, path string) bool {
+	return group.router.Remove(method, joinPaths(group.prefix, path), group.options(nil)...)
+}
+
+// Mount serves every request beneath the group's prefix and the path with the handler.
+// See Router.Mount
+func (group *Group) Mount(path string, handler http.Handler) {
+	if nilHandler(handler) {
+		panic("received and empty handler")
+	}
+
+	prefix := strings.TrimSuffix(joinPaths(group.prefix, path), "/")
+	mountHandlerFunc := mountHandler(handler)
+	options := group.options([]RouteOption{withMounted()})
+
+	for _, method := range mountMethods {
+		if prefix != "" {
+			group.router.HandleFunc(method, prefix, mountHandlerFunc, options...)
+		}
+
+		group.router.HandleFunc(method, prefix+"/*"+mountPathParameter, mountHandlerFunc, options...)
+	}
+}
+
+// options adds the group's host and middleware in front of a route's options
+func (group *Group) options(options []RouteOption) []RouteOption {
+	if group.host != "" {
+		options = append([]RouteOption{WithHost(group.host)}, options...)
+	}
+
+	if len(group.middleware) == 0 {
+		return options
+	}
+
+	return append([]RouteOption{WithMiddleware(group.middleware...)}, options...)
+}
+
+// withMounted marks the routes registered by Mount, so they can be left out of the
+// OpenAPI document
+func withMounted() RouteOption {
+	return func(newEndpoint *endpoint) {
+		newEndpoint.mounted = true
+	}
+}
+
+// mountHandler strips the mounted prefix from the request's path before calling the handler
+func mountHandler(handler http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info, _ := GetRouteInfo(r.Context())
+		remaining, _ := info.Params.Get(mountPathParameter)
+
+		mountedURL := new(url.URL)
+		*mountedURL = *r.URL
+		mountedURL.Path = "/" + remaining
+		mountedURL.RawPath = ""
+
+		info.Pattern = strings.TrimSuffix(info.Pattern, "*"+mountPathParameter)
+		info.Params = info.Params.without(mountPathParameter)
+		info.Params.pattern = info.Pattern
+		mountedRequest := r.WithContext(context.WithValue(r.Context(), NAMED_PAAMTERS, &info))
+		mountedRequest.URL = mountedURL
+
+		handler.ServeHTTP(w, mountedRequest)
+	}
+}
+
+// joinPaths appends a path to a prefix, with a single '/' between them
+func joinPaths(prefix, path string) string {
+	switch {
+	case prefix == "" || path == "":
+		return prefix + path
+	case strings.HasSuffix(prefix, "/") && strings.HasPrefix(path, "/"):
+		return prefix + path[1:]
+	case !strings.HasSuffix(prefix, "/") && !strings.HasPrefix(path, "/"):
+		return prefix + "/" + path
+	default:
+		return prefix + path
+	}
+}
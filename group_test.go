@@ -0,0 +1,165 @@
+package urlrouter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestGroup_HandleFunc(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	var namedParameters = map[string]string{}
+	foundHandler := func(path string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			namedParameters = GetNamedParamters(r.Context())
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(path))
+		}
+	}
+
+	headerMiddleware := func(value string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middleware", value)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	t.Run("It panics if the handler is empty", func(t *testing.T) {
+		router := New()
+		group := router.Group("/v1")
+		group.Use(headerMiddleware("v1"))
+		g.Expect(func() { group.HandleFunc("POST", "/something", nil) }).To(Panic())
+	})
+
+	router := New()
+	router.HandleFunc("GET", "/health", foundHandler("/health"))
+
+	v1 := router.Group("/v1")
+	v1.Use(headerMiddleware("v1"))
+	v1.HandleFunc("GET", "/users", foundHandler("/v1/users"))
+
+	v2 := router.Group("/v2/")
+	v2.Use(headerMiddleware("v2"))
+	v2.HandleFunc("GET", "/users", foundHandler("/v2/users"))
+	v2.HandleFunc("GET", "groups", foundHandler("/v2/groups"))
+
+	v3 := router.Group("/v3")
+	g.Expect(v3.Add("GET", "users", foundHandler("/v3/users"))).ToNot(HaveOccurred())
+	v3.Group("admin").HandleFunc("GET", "roles", foundHandler("/v3/admin/roles"))
+
+	tenants := v2.Group("/tenants/:tenant")
+	tenants.Use(headerMiddleware("tenants"))
+	tenants.HandleFunc("GET", "/users/:id", foundHandler("/v2/tenants/:tenant/users/:id"))
+
+	testServer := httptest.NewServer(router)
+	defer testServer.Close()
+
+	tests := []struct {
+		path            string
+		expected        string
+		middleware      []string
+		namedParameters map[string]string
+	}{
+		{path: "/health", expected: "/health", middleware: nil, namedParameters: nil},
+		{path: "/v1/users", expected: "/v1/users", middleware: []string{"v1"}, namedParameters: nil},
+		{path: "/v2/users", expected: "/v2/users", middleware: []string{"v2"}, namedParameters: nil},
+		{path: "/v2/groups", expected: "/v2/groups", middleware: []string{"v2"}, namedParameters: nil},
+		{path: "/v3/users", expected: "/v3/users", middleware: nil, namedParameters: nil},
+		{path: "/v3/admin/roles", expected: "/v3/admin/roles", middleware: nil, namedParameters: nil},
+		{path: "/v2/tenants/acme/users/42", expected: "/v2/tenants/:tenant/users/:id", middleware: []string{"v2", "tenants"}, namedParameters: map[string]string{"tenant": "acme", "id": "42"}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("It serves %s with the group's middleware", test.path), func(t *testing.T) {
+			namedParameters = map[string]string{}
+
+			request, err := http.NewRequest("GET", fmt.Sprintf("%s%s", testServer.URL, test.path), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			g.Expect(resp.Header.Values("X-Middleware")).To(Equal(test.middleware))
+			g.Expect(namedParameters).To(Equal(test.namedParameters))
+
+			body, err := io.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(body)).To(Equal(test.expected))
+		})
+	}
+}
+
+func TestGroup_Mount(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	mountedHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(fmt.Sprintf("%s %s %v", r.Method, r.URL.Path, GetNamedParamters(r.Context()))))
+	}
+
+	t.Run("It panics if the handler is empty", func(t *testing.T) {
+		router := New()
+		g.Expect(func() { router.Mount("/static", nil) }).To(Panic())
+		g.Expect(func() { router.Mount("/static", http.HandlerFunc(nil)) }).To(Panic())
+		g.Expect(router.Routes()).To(BeEmpty())
+	})
+
+	subRouter := New()
+	subRouter.HandleFunc("GET", "/users/:id", mountedHandler)
+	subRouter.HandleFunc("DELETE", "/users/:id", mountedHandler)
+
+	router := New()
+	router.HandleFunc("GET", "/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("catch all"))
+	})
+	router.Mount("/static", http.HandlerFunc(mountedHandler))
+	router.Group("/tenants/:tenant").Mount("/api", subRouter)
+
+	testServer := httptest.NewServer(router)
+	defer testServer.Close()
+
+	tests := []struct {
+		method   string
+		path     string
+		status   int
+		expected string
+	}{
+		{method: "GET", path: "/static", status: http.StatusOK, expected: "GET / map[]"},
+		{method: "GET", path: "/static/", status: http.StatusOK, expected: "GET / map[]"},
+		{method: "POST", path: "/static/css/site.css", status: http.StatusOK, expected: "POST /css/site.css map[]"},
+		{method: "GET", path: "/staticfiles", status: http.StatusOK, expected: "catch all"},
+		{method: "GET", path: "/tenants/acme/api/users/42", status: http.StatusOK, expected: "GET /users/42 map[id:42 tenant:acme]"},
+		{method: "DELETE", path: "/tenants/acme/api/users/42", status: http.StatusOK, expected: "DELETE /users/42 map[id:42 tenant:acme]"},
+		{method: "PUT", path: "/tenants/acme/api/users/42", status: http.StatusMethodNotAllowed},
+		{method: "GET", path: "/tenants/acme/api/unknown", status: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("It serves %s %s", test.method, test.path), func(t *testing.T) {
+			request, err := http.NewRequest(test.method, fmt.Sprintf("%s%s", testServer.URL, test.path), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(test.status))
+
+			if test.status == http.StatusOK {
+				body, err := io.ReadAll(resp.Body)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(string(body)).To(Equal(test.expected))
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package urlrouter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// host holds the routes for the requests to hosts that match a pattern
+type host struct {
+	pattern string
+	labels  []hostLabel
+	routes  routes
+}
+
+// hostLabel is a single label of a host pattern, which is either a static label such as
+// "api", or a named parameter such as ":tenant"
+type hostLabel struct {
+	label      string
+	name       string
+	constraint Constraint
+}
+
+// WithHost registers the route for the requests to hosts that match the pattern, such as
+// "api.example.com" or ":tenant.api.example.com". Named parameters match a single label of
+// the host and can be constrained like the named parameters of a path. The host's named
+// parameters are merged into the route's named parameters, with the path's named
+// parameters taking priority for the same name.
+//
+// Each host has its own routes. A request uses the routes of the first host that matches,
+// trying hosts without named parameters first, and otherwise the routes registered without
+// a host. Hosts are matched without their port and ignoring case.
+func WithHost(pattern string) RouteOption {
+	return func(newEndpoint *endpoint) {
+		newEndpoint.host = hostPattern(pattern)
+	}
+}
+
+// hostPattern lower cases the static labels of a host pattern, leaving the named parameters
+// and their constraints as they were written
+func hostPattern(pattern string) string {
+	labels := strings.Split(pattern, ".")
+	for index, label := range labels {
+		if !strings.HasPrefix(label, ":") {
+			labels[index] = strings.ToLower(label)
+		}
+	}
+
+	return strings.Join(labels, ".")
+}
+
+// Host creates a group of routes for the requests to hosts that match the pattern. See WithHost
+func (router *Router) Host(pattern string) *Group {
+	return &Group{router: router, host: pattern}
+}
+
+// newHost parses a host pattern. This errors if a label is empty or has an invalid
+// constraint, or if the pattern uses the same name more than once
+func newHost(pattern string, constraints Constraints) (*host, error) {
+	newHost := &host{pattern: pattern, routes: routes{}}
+
+	for _, label := range strings.Split(pattern, ".") {
+		if !strings.HasPrefix(label, ":") {
+			if label == "" {
+				return nil, fmt.Errorf("%w: host %q has an empty label", ErrInvalidPattern, pattern)
+			}
+
+			newHost.labels = append(newHost.labels, hostLabel{label: label})
+			continue
+		}
+
+		name, _, constraint, err := parseNamedParameter(label, constraints)
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "" {
+			return nil, fmt.Errorf("%w: host %q has a named parameter without a name", ErrInvalidPattern, pattern)
+		}
+
+		for _, known := range newHost.labels {
+			if known.name == name {
+				return nil, fmt.Errorf("%w: host %q uses the named parameter %q more than once", ErrConflictingParams, pattern, name)
+			}
+		}
+
+		newHost.labels = append(newHost.labels, hostLabel{name: name, constraint: constraint})
+	}
+
+	return newHost, nil
+}
+
+// named reports if the host's pattern has any named parameters
+func (h *host) named() bool {
+	for _, label := range h.labels {
+		if label.name != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match reports if the host name matches the host's pattern, along with the host's named parameters
+func (h *host) match(hostname string) (Params, bool) {
+	labels := strings.Split(hostname, ".")
+	if len(labels) != len(h.labels) {
+		return Params{}, false
+	}
+
+	params := Params{}
+	for index, label := range h.labels {
+		value := labels[index]
+
+		switch {
+		case label.name == "":
+			if label.label != value {
+				return Params{}, false
+			}
+		case value == "" || (label.constraint != nil && !label.constraint(value)):
+			return Params{}, false
+		default:
+			params.names = append(params.names, label.name)
+			params.values = append(params.values, value)
+		}
+	}
+
+	return params, true
+}
+
+// hostname returns the request's host without the port, in lower case
+func hostname(r *http.Request) string {
+	name := r.Host
+	if index := strings.LastIndexByte(name, ':'); index > strings.LastIndexByte(name, ']') {
+		name = name[:index]
+	}
+
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
+
+// hostRoutes returns the routes to use for the request, along with the named parameters of
+// the matched host. These are the default routes when no host matches
+func (t *table) hostRoutes(r *http.Request) (routes, Params) {
+	if len(t.hosts) == 0 {
+		return t.routes, Params{}
+	}
+
+	name := hostname(r)
+	for _, known := range t.hosts {
+		if params, ok := known.match(name); ok {
+			return known.routes, params
+		}
+	}
+
+	return t.routes, Params{}
+}
+
+// hostTree returns the routes of a host that can be changed, adding the host when it is new.
+// Hosts without named parameters are kept in front of the hosts with named parameters
+func (t *table) hostTree(pattern string, constraints Constraints) (routes, error) {
+	if pattern == "" {
+		return t.routes, nil
+	}
+
+	for index, known := range t.hosts {
+		if known.pattern == pattern {
+			updated := &host{pattern: known.pattern, labels: known.labels, routes: make(routes, len(known.routes))}
+			for method, route := range known.routes {
+				updated.routes[method] = route
+			}
+
+			t.hosts[index] = updated
+			return updated.routes, nil
+		}
+	}
+
+	added, err := newHost(pattern, constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	index := len(t.hosts)
+	if !added.named() {
+		for index > 0 && t.hosts[index-1].named() {
+			index--
+		}
+	}
+
+	t.hosts = append(t.hosts[:index], append([]*host{added}, t.hosts[index:]...)...)
+	return added.routes, nil
+}
+
+// trees returns the default routes followed by the routes of each host
+func (t *table) trees() []routes {
+	trees := []routes{t.routes}
+	for _, known := range t.hosts {
+		trees = append(trees, known.routes)
+	}
+
+	return trees
+}
+
+// with returns the parameters with the other parameters added. The other parameters replace
+// any parameters with the same name. The parameters are never modified
+func (p Params) with(other Params) Params {
+	if p.Len() == 0 {
+		return other
+	}
+
+	params := Params{pattern: p.pattern}
+	for index, name := range p.names {
+		if !contains(other.names, name) {
+			params.names = append(params.names, name)
+			params.values = append(params.values, p.values[index])
+		}
+	}
+
+	params.names = append(params.names, other.names...)
+	params.values = append(params.values, other.values...)
+	return params
+}
@@ -0,0 +1,188 @@
+package urlrouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRouter_Hosts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	infoHandler := func(w http.ResponseWriter, r *http.Request) {
+		info, _ := GetRouteInfo(r.Context())
+		w.Header().Set("X-Host", info.Host)
+		w.Header().Set("X-Pattern", info.Pattern)
+		for name, value := range info.Params.Map() {
+			w.Header().Set("X-Param-"+name, value)
+		}
+	}
+
+	serve := func(router *Router, method, target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+		return recorder
+	}
+
+	setupRouter := func() *Router {
+		router := New()
+		router.HandleFunc("GET", "/", infoHandler)
+		router.HandleFunc("GET", "/health", infoHandler)
+
+		tenants := router.Host(":tenant.api.example.com")
+		tenants.HandleFunc("GET", "/users/:id", infoHandler)
+		tenants.HandleFunc("GET", "/tenant/:tenant", infoHandler)
+
+		router.HandleFunc("GET", "/users/:id", infoHandler, WithHost("Admin.API.example.com"))
+		router.HandleFunc("GET", "/orders", infoHandler, WithHost("api.:region<alpha>.example.com"))
+		return router
+	}
+
+	t.Run("It routes requests by their host", func(t *testing.T) {
+		router := setupRouter()
+
+		recorder := serve(router, "GET", "http://acme.api.example.com/users/42")
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Header().Get("X-Host")).To(Equal(":tenant.api.example.com"))
+		g.Expect(recorder.Header().Get("X-Param-tenant")).To(Equal("acme"))
+		g.Expect(recorder.Header().Get("X-Param-id")).To(Equal("42"))
+
+		recorder = serve(router, "GET", "http://api.eu.example.com:8080/orders")
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Header().Get("X-Param-region")).To(Equal("eu"))
+	})
+
+	t.Run("It prefers hosts without named parameters and ignores case and ports", func(t *testing.T) {
+		router := setupRouter()
+
+		recorder := serve(router, "GET", "http://ADMIN.api.example.com:443/users/42")
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Header().Get("X-Host")).To(Equal("admin.api.example.com"))
+		g.Expect(recorder.Header().Get("X-Param-tenant")).To(BeEmpty())
+	})
+
+	t.Run("It only ignores the case of the host's static labels", func(t *testing.T) {
+		router := New(WithConstraints(Constraints{"tenantID": func(value string) bool { return strings.HasPrefix(value, "t") }}))
+		router.HandleFunc("GET", "/", infoHandler, WithHost(`:name{\D+}.Example.com`))
+		router.HandleFunc("GET", "/", infoHandler, WithHost(":tenant<tenantID>.API.example.com"))
+
+		recorder := serve(router, "GET", "http://ACME.example.com/")
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Header().Get("X-Host")).To(Equal(`:name{\D+}.example.com`))
+		g.Expect(recorder.Header().Get("X-Param-name")).To(Equal("acme"))
+		g.Expect(serve(router, "GET", "http://42.example.com/").Code).To(Equal(http.StatusNotFound))
+
+		recorder = serve(router, "GET", "http://t1.api.example.com/")
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Header().Get("X-Host")).To(Equal(":tenant<tenantID>.api.example.com"))
+		g.Expect(recorder.Header().Get("X-Param-tenant")).To(Equal("t1"))
+	})
+
+	t.Run("It falls back to the default routes when no host matches", func(t *testing.T) {
+		router := setupRouter()
+
+		recorder := serve(router, "GET", "http://example.com/health")
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Header().Get("X-Host")).To(BeEmpty())
+
+		g.Expect(serve(router, "GET", "http://example.com/users/42").Header().Get("X-Pattern")).To(Equal("/"))
+		g.Expect(serve(router, "GET", "http://api.e1.example.com/orders").Header().Get("X-Pattern")).To(Equal("/"))
+		g.Expect(serve(router, "GET", "http://a.b.api.example.com/users/42").Header().Get("X-Pattern")).To(Equal("/"))
+	})
+
+	t.Run("It only uses the routes of the matched host", func(t *testing.T) {
+		router := setupRouter()
+
+		g.Expect(serve(router, "GET", "http://acme.api.example.com/health").Code).To(Equal(http.StatusNotFound))
+
+		recorder := serve(router, "POST", "http://acme.api.example.com/users/42")
+		g.Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		g.Expect(recorder.Header().Get("Allow")).To(Equal("GET, HEAD, OPTIONS"))
+	})
+
+	t.Run("It gives the path's named parameters priority over the host's", func(t *testing.T) {
+		router := setupRouter()
+
+		recorder := serve(router, "GET", "http://acme.api.example.com/tenant/other")
+		g.Expect(recorder.Header().Get("X-Param-tenant")).To(Equal("other"))
+	})
+
+	t.Run("It applies groups and mounts to the host", func(t *testing.T) {
+		router := New()
+		group := router.Host(":tenant.example.com").Group("/v1")
+		group.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Middleware", "group")
+				next.ServeHTTP(w, r)
+			})
+		})
+		group.HandleFunc("GET", "/users", infoHandler)
+		group.Mount("/static", http.HandlerFunc(infoHandler))
+
+		recorder := serve(router, "GET", "http://acme.example.com/v1/users")
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Header().Get("X-Middleware")).To(Equal("group"))
+
+		recorder = serve(router, "GET", "http://acme.example.com/v1/static/app.js")
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Header().Get("X-Param-tenant")).To(Equal("acme"))
+
+		g.Expect(serve(router, "GET", "http://example.com/v1/users").Code).To(Equal(http.StatusNotFound))
+	})
+
+	t.Run("It lists the routes of every host", func(t *testing.T) {
+		router := setupRouter()
+
+		var hosts []string
+		for _, route := range router.Routes() {
+			hosts = append(hosts, route.Host+" "+route.Pattern)
+		}
+
+		g.Expect(hosts).To(Equal([]string{
+			" /health",
+			" /",
+			"admin.api.example.com /users/:id",
+			":tenant.api.example.com /tenant/:tenant",
+			":tenant.api.example.com /users/:id",
+			"api.:region<alpha>.example.com /orders",
+		}))
+	})
+
+	t.Run("It removes the routes of a host and prunes the host once it is empty", func(t *testing.T) {
+		router := setupRouter()
+		router.HandleFunc("GET", "/users/:id", infoHandler, WithHost("admin.api.example.com"), WithName("admin"))
+
+		g.Expect(router.Remove("GET", "/users/:id")).To(BeFalse())
+		g.Expect(router.Remove("GET", "/users/:id", WithHost("other.example.com"))).To(BeFalse())
+		g.Expect(router.Remove("GET", "/users/:id", WithHost("ADMIN.api.example.com"))).To(BeTrue())
+		g.Expect(router.table.Load().hosts).To(HaveLen(2))
+
+		_, err := router.URL("admin", "id", "42")
+		g.Expect(err).To(MatchError(ErrUnknownRoute))
+
+		// the request now falls back to the host with named parameters
+		g.Expect(serve(router, "GET", "http://admin.api.example.com/users/42").Header().Get("X-Host")).To(Equal(":tenant.api.example.com"))
+
+		tenants := router.Host(":tenant.api.example.com")
+		g.Expect(tenants.Remove("GET", "/users/:id")).To(BeTrue())
+		g.Expect(serve(router, "GET", "http://acme.api.example.com/users/42").Code).To(Equal(http.StatusNotFound))
+		g.Expect(serve(router, "GET", "http://acme.api.example.com/tenant/acme").Code).To(Equal(http.StatusOK))
+
+		g.Expect(tenants.Remove("GET", "/tenant/:tenant")).To(BeTrue())
+		g.Expect(router.table.Load().hosts).To(HaveLen(1))
+		g.Expect(serve(router, "GET", "http://acme.api.example.com/health").Code).To(Equal(http.StatusOK))
+		g.Expect(serve(router, "GET", "http://api.eu.example.com/orders").Code).To(Equal(http.StatusOK))
+	})
+
+	t.Run("It errors on invalid host patterns", func(t *testing.T) {
+		router := New()
+		g.Expect(router.Host("api..example.com").Add("GET", "/", http.HandlerFunc(infoHandler))).To(MatchError(ErrInvalidPattern))
+		g.Expect(router.Host(":.example.com").Add("GET", "/", http.HandlerFunc(infoHandler))).To(MatchError(ErrInvalidPattern))
+		g.Expect(router.Host(":id<even>.example.com").Add("GET", "/", http.HandlerFunc(infoHandler))).To(MatchError(ErrInvalidPattern))
+		g.Expect(router.Host(":a.:a.example.com").Add("GET", "/", http.HandlerFunc(infoHandler))).To(MatchError(ErrConflictingParams))
+		g.Expect(router.Routes()).To(BeEmpty())
+	})
+}
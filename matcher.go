@@ -0,0 +1,255 @@
+package urlrouter
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Matcher is a condition a request must meet to be served by a route. Routes registered for
+// the same method and path with different matchers are tried in the order they were
+// registered, and the route without any matchers is always tried last
+type Matcher struct {
+	name   string
+	status int
+	match  func(r *http.Request) bool
+}
+
+// WithMatchers adds conditions that a request must meet to be served by the route. When a
+// request's path matches, but none of the routes' conditions pass, the router's MatcherFailed
+// handler is called, which defaults to answering with the status of the first condition
+// that failed, such as 415 for MatchContentType
+func WithMatchers(matchers ...Matcher) RouteOption {
+	return func(newEndpoint *endpoint) {
+		newEndpoint.matchers = append(newEndpoint.matchers, matchers...)
+	}
+}
+
+// MatchFunc creates a custom condition. The name describes the condition, so a route
+// registered again with matchers of the same names replaces the previous route. The status
+// is used when no route's conditions pass, and defaults to 406 Not Acceptable
+func MatchFunc(name string, status int, match func(r *http.Request) bool) Matcher {
+	if status == 0 {
+		status = http.StatusNotAcceptable
+	}
+
+	return Matcher{name: name, status: status, match: match}
+}
+
+// MatchHeader requires the request's header to have the value. When the value is empty, the
+// header only needs to be set. Requests that fail are answered with 406 Not Acceptable
+func MatchHeader(name, value string) Matcher {
+	return MatchFunc(fmt.Sprintf("header %s=%s", http.CanonicalHeaderKey(name), value), http.StatusNotAcceptable, func(r *http.Request) bool {
+		values, ok := r.Header[http.CanonicalHeaderKey(name)]
+		return ok && (value == "" || contains(values, value))
+	})
+}
+
+// MatchQuery requires the query parameter to have the value. When the value is empty, the
+// query parameter only needs to be set. Requests that fail are answered with 406 Not Acceptable
+func MatchQuery(name, value string) Matcher {
+	return MatchFunc(fmt.Sprintf("query %s=%s", name, value), http.StatusNotAcceptable, func(r *http.Request) bool {
+		values, ok := r.URL.Query()[name]
+		return ok && (value == "" || contains(values, value))
+	})
+}
+
+// MatchContentType requires the request's body to have one of the media types, such as
+// "application/json". A media type can end in "/*" to match any subtype. Requests that fail
+// are answered with 415 Unsupported Media Type
+func MatchContentType(mediaTypes ...string) Matcher {
+	return MatchFunc(fmt.Sprintf("content-type %s", strings.Join(mediaTypes, ",")), http.StatusUnsupportedMediaType, func(r *http.Request) bool {
+		contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			return false
+		}
+
+		for _, mediaType := range mediaTypes {
+			if mediaTypeMatches(mediaType, contentType) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// MatchAccept requires the request's Accept header to accept one of the media types, such as
+// "application/vnd.example.v2+json". A request without an Accept header accepts any media
+// type. Requests that fail are answered with 406 Not Acceptable
+func MatchAccept(mediaTypes ...string) Matcher {
+	return MatchFunc(fmt.Sprintf("accept %s", strings.Join(mediaTypes, ",")), http.StatusNotAcceptable, func(r *http.Request) bool {
+		accept := r.Header.Values("Accept")
+		if len(accept) == 0 {
+			return true
+		}
+
+		for _, mediaRange := range strings.Split(strings.Join(accept, ","), ",") {
+			accepted, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil {
+				continue
+			}
+
+			if quality, ok := params["q"]; ok {
+				if weight, err := strconv.ParseFloat(quality, 64); err != nil || weight <= 0 {
+					continue
+				}
+			}
+
+			for _, mediaType := range mediaTypes {
+				if mediaTypeMatches(accepted, mediaType) {
+					return true
+				}
+			}
+		}
+
+		return false
+	})
+}
+
+// mediaTypeMatches reports if the media type is in the media range, such as "text/*"
+func mediaTypeMatches(mediaRange, mediaType string) bool {
+	mediaRange, mediaType = strings.ToLower(mediaRange), strings.ToLower(mediaType)
+
+	switch {
+	case mediaRange == "*/*":
+		return true
+	case strings.HasSuffix(mediaRange, "/*"):
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*"))
+	default:
+		return mediaRange == mediaType
+	}
+}
+
+// candidates are the endpoints registered for the same route, which are chosen between by
+// their matchers. Candidates with matchers are kept in the order they were registered, in
+// front of the candidate without any matchers
+type candidates []*endpoint
+
+// conditions describes the matchers of the endpoint, ignoring their order
+func (e *endpoint) conditions() string {
+	names := make([]string, 0, len(e.matchers))
+	for _, matcher := range e.matchers {
+		names = append(names, matcher.name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, "\n")
+}
+
+// add returns a copy of the candidates that includes the endpoint. An endpoint with the same
+// conditions is replaced, see endpoint.replace. Every candidate must use the same parameter
+// names, since the names of a route can't depend on the request's headers
+func (c candidates) add(newEndpoint *endpoint, overwrite bool) (candidates, error) {
+	conditions := newEndpoint.conditions()
+
+	for _, existing := range c {
+		if !equalNames(existing.paramNames, newEndpoint.paramNames) {
+			return nil, fmt.Errorf("%w: the route is already registered as %q at %s", ErrConflictingParams, existing.pattern, existing.source)
+		}
+	}
+
+	for index, existing := range c {
+		if existing.conditions() == conditions {
+			replaced, err := newEndpoint.replace(existing, overwrite)
+			if err != nil {
+				return nil, err
+			}
+
+			updated := append(candidates(nil), c...)
+			updated[index] = replaced
+			return updated, nil
+		}
+	}
+
+	index := len(c)
+	if len(newEndpoint.matchers) != 0 && index > 0 && len(c[index-1].matchers) == 0 {
+		index--
+	}
+
+	updated := make(candidates, 0, len(c)+1)
+	updated = append(updated, c[:index]...)
+	updated = append(updated, newEndpoint)
+	return append(updated, c[index:]...), nil
+}
+
+// without returns the candidates that were not registered with the pattern, along with the
+// candidates that were
+func (c candidates) without(pattern string) (candidates, candidates) {
+	var kept, removed candidates
+	for _, candidate := range c {
+		if candidate.pattern == pattern {
+			removed = append(removed, candidate)
+		} else {
+			kept = append(kept, candidate)
+		}
+	}
+
+	return kept, removed
+}
+
+// find returns the first candidate registered with the pattern
+func (c candidates) find(pattern string) *endpoint {
+	for _, candidate := range c {
+		if candidate.pattern == pattern {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+// choose returns the first candidate whose matchers all pass. When none pass, this returns
+// the status of the first matcher that failed
+func (c candidates) choose(r *http.Request) (*endpoint, int) {
+	status := 0
+
+	for _, candidate := range c {
+		passed := true
+		for _, matcher := range candidate.matchers {
+			if !matcher.match(r) {
+				if status == 0 {
+					status = matcher.status
+				}
+
+				passed = false
+				break
+			}
+		}
+
+		if passed {
+			return candidate, 0
+		}
+	}
+
+	return nil, status
+}
+
+// GetMatcherStatus returns the status of the first matcher that failed, when a route matched
+// the request's path but none of its matchers passed. This is only set for the MatcherFailed
+// handler, and is 0 otherwise
+func GetMatcherStatus(ctx context.Context) int {
+	if status, ok := ctx.Value(MATCHER_STATUS).(int); ok {
+		return status
+	}
+
+	return 0
+}
+
+// unmatched returns the handler for a request whose route's matchers did not pass, along
+// with the request updated to include the status of the first matcher that failed
+func (router *Router) unmatched(status int, r *http.Request) (http.Handler, *http.Request) {
+	r = r.WithContext(context.WithValue(r.Context(), MATCHER_STATUS, status))
+
+	if router.MatcherFailed != nil {
+		return router.MatcherFailed, r
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, http.StatusText(status), status)
+	}), r
+}
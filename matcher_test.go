@@ -0,0 +1,167 @@
+package urlrouter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRouter_Matchers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	respond := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id, _ := GetParams(r.Context()).Get("id")
+			_, _ = w.Write([]byte(body + " " + id))
+		}
+	}
+
+	serve := func(router *Router, request *http.Request) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("It chooses the route by the request's content type", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("POST", "/users/:id", respond("json"), WithMatchers(MatchContentType("application/json")))
+		router.HandleFunc("POST", "/users/:id", respond("form"), WithMatchers(MatchContentType("application/x-www-form-urlencoded", "multipart/*")))
+
+		request := httptest.NewRequest("POST", "/users/42", strings.NewReader("{}"))
+		request.Header.Set("Content-Type", "application/json; charset=utf-8")
+		recorder := serve(router, request)
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Body.String()).To(Equal("json 42"))
+
+		request = httptest.NewRequest("POST", "/users/42", nil)
+		request.Header.Set("Content-Type", "multipart/form-data; boundary=xyz")
+		recorder = serve(router, request)
+		g.Expect(recorder.Body.String()).To(Equal("form 42"))
+
+		request = httptest.NewRequest("POST", "/users/42", nil)
+		request.Header.Set("Content-Type", "text/plain")
+		recorder = serve(router, request)
+		g.Expect(recorder.Code).To(Equal(http.StatusUnsupportedMediaType))
+
+		recorder = serve(router, httptest.NewRequest("POST", "/users/42", nil))
+		g.Expect(recorder.Code).To(Equal(http.StatusUnsupportedMediaType))
+	})
+
+	t.Run("It chooses the route by the version in the Accept header", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users/:id", respond("v1"), WithMatchers(MatchAccept("application/vnd.example.v1+json")))
+		router.HandleFunc("GET", "/users/:id", respond("v2"), WithMatchers(MatchAccept("application/vnd.example.v2+json")))
+
+		request := httptest.NewRequest("GET", "/users/42", nil)
+		request.Header.Set("Accept", "text/html, application/vnd.example.v2+json;q=0.9")
+		g.Expect(serve(router, request).Body.String()).To(Equal("v2 42"))
+
+		request = httptest.NewRequest("GET", "/users/42", nil)
+		request.Header.Set("Accept", "application/vnd.example.v2+json;q=0, application/*")
+		g.Expect(serve(router, request).Body.String()).To(Equal("v1 42"))
+
+		// without an Accept header, the first route registered is used
+		g.Expect(serve(router, httptest.NewRequest("GET", "/users/42", nil)).Body.String()).To(Equal("v1 42"))
+
+		request = httptest.NewRequest("GET", "/users/42", nil)
+		request.Header.Set("Accept", "application/vnd.example.v2+json;q=0, text/html")
+		recorder := serve(router, request)
+		g.Expect(recorder.Code).To(Equal(http.StatusNotAcceptable))
+
+		// the automatic HEAD response uses the same matchers
+		request = httptest.NewRequest("HEAD", "/users/42", nil)
+		request.Header.Set("Accept", "text/html")
+		g.Expect(serve(router, request).Code).To(Equal(http.StatusNotAcceptable))
+	})
+
+	t.Run("It chooses the route by a header or query flag, and falls back to the route without matchers", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/reports/*path", respond("default"))
+		router.HandleFunc("GET", "/reports/*path", respond("beta"), WithMatchers(MatchQuery("beta", "")))
+		router.HandleFunc("GET", "/reports/*path", respond("v2"), WithMatchers(MatchHeader("x-api-version", "2")))
+
+		g.Expect(serve(router, httptest.NewRequest("GET", "/reports/daily", nil)).Body.String()).To(Equal("default "))
+		g.Expect(serve(router, httptest.NewRequest("GET", "/reports/daily?beta", nil)).Body.String()).To(Equal("beta "))
+
+		request := httptest.NewRequest("GET", "/reports/daily?beta=1", nil)
+		request.Header.Set("X-Api-Version", "2")
+		g.Expect(serve(router, request).Body.String()).To(Equal("beta "))
+
+		request = httptest.NewRequest("GET", "/reports/daily", nil)
+		request.Header.Set("X-Api-Version", "2")
+		g.Expect(serve(router, request).Body.String()).To(Equal("v2 "))
+	})
+
+	t.Run("It uses the status of a custom matcher", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/admin", respond("admin"), WithMatchers(MatchFunc("authorized", http.StatusForbidden, func(r *http.Request) bool {
+			return r.Header.Get("Authorization") != ""
+		})))
+
+		g.Expect(serve(router, httptest.NewRequest("GET", "/admin", nil)).Code).To(Equal(http.StatusForbidden))
+		g.Expect(serve(router, httptest.NewRequest("POST", "/admin", nil)).Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	t.Run("It calls the MatcherFailed handler with the status of the failed matcher", func(t *testing.T) {
+		router := New()
+		router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+		router.MatcherFailed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(GetMatcherStatus(r.Context()))
+			_, _ = w.Write([]byte(`{"error":"unsupported"}`))
+		})
+		router.HandleFunc("POST", "/users/:id", respond("json"), WithMatchers(MatchContentType("application/json")))
+
+		request := httptest.NewRequest("POST", "/users/42", nil)
+		request.Header.Set("Content-Type", "text/plain")
+		recorder := serve(router, request)
+		g.Expect(recorder.Code).To(Equal(http.StatusUnsupportedMediaType))
+		g.Expect(recorder.Body.String()).To(Equal(`{"error":"unsupported"}`))
+
+		g.Expect(serve(router, httptest.NewRequest("POST", "/unknown", nil)).Code).To(Equal(http.StatusTeapot))
+		g.Expect(GetMatcherStatus(request.Context())).To(BeZero())
+	})
+
+	t.Run("It replaces a route registered with the same matchers, but never renames its parameters", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users/:id", respond("first"), WithMatchers(MatchHeader("X-Version", "1"), MatchQuery("pretty", "")))
+		router.HandleFunc("GET", "/users/:id", respond("second"), WithMatchers(MatchQuery("pretty", ""), MatchHeader("X-Version", "1")))
+
+		request := httptest.NewRequest("GET", "/users/42?pretty", nil)
+		request.Header.Set("X-Version", "1")
+		g.Expect(serve(router, request).Body.String()).To(Equal("second 42"))
+
+		err := router.Add("GET", "/users/:id", respond("third"), WithMatchers(MatchHeader("X-Version", "1"), MatchQuery("pretty", "")))
+		g.Expect(errors.Is(err, ErrDuplicateRoute)).To(BeTrue())
+
+		err = router.Add("GET", "/users/:userID", respond("third"), WithMatchers(MatchHeader("X-Version", "2")))
+		g.Expect(errors.Is(err, ErrConflictingParams)).To(BeTrue())
+		g.Expect(err.Error()).To(ContainSubstring(`"/users/:id"`))
+	})
+
+	t.Run("It removes and walks every route for the pattern", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users", respond("json"), WithMatchers(MatchAccept("application/json")), WithName("users"))
+		router.HandleFunc("GET", "/users", respond("html"))
+
+		var patterns []string
+		_ = router.Walk(func(method, pattern string, handler http.Handler) error {
+			patterns = append(patterns, pattern)
+			return nil
+		})
+		g.Expect(patterns).To(Equal([]string{"/users", "/users"}))
+
+		g.Expect(router.Remove("GET", "/users")).To(BeTrue())
+		g.Expect(router.Routes()).To(BeEmpty())
+		g.Expect(serve(router, httptest.NewRequest("GET", "/users", nil)).Code).To(Equal(http.StatusNotFound))
+
+		_, err := router.URL("users")
+		g.Expect(err).To(HaveOccurred())
+	})
+}
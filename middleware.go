@@ -0,0 +1,69 @@
+package urlrouter
+
+import "net/http"
+
+// Middleware wraps the handler of a route. Middleware runs after the request was matched
+// to a route, so GetRouteInfo can be used to read the matched pattern and named parameters
+type Middleware func(http.Handler) http.Handler
+
+// RouteOption configures a single route when it is registered
+type RouteOption func(newEndpoint *endpoint)
+
+// WithMiddleware wraps the route's handler with middleware. The first middleware is the
+// outer most, and runs after any middleware added to the Router or Group
+func WithMiddleware(middleware ...Middleware) RouteOption {
+	return func(newEndpoint *endpoint) {
+		newEndpoint.middleware = append(newEndpoint.middleware, middleware...)
+	}
+}
+
+// WithName names the route, so a url for it can be built with Router.URL. The same name can
+// be used for multiple methods, but only with the same pattern
+func WithName(name string) RouteOption {
+	return func(newEndpoint *endpoint) {
+		newEndpoint.name = name
+	}
+}
+
+// Use adds middleware that wraps every request served by the router, including requests
+// that are not found or not allowed. The middleware runs in the order it was added, before
+// any group or route middleware. Each middleware is built once, when Use is called, so
+// middleware that keeps state, such as a rate limiter, keeps it across requests
+func (router *Router) Use(middleware ...Middleware) {
+	_ = router.update(func(updated *table) error {
+		next := len(updated.middleware) + 1
+		layer := chain(middleware, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serveNext(next, w, r)
+		}))
+
+		// copy the middleware, since requests being served might still be using it
+		updated.middleware = append(append([]http.Handler{}, updated.middleware...), layer)
+		return nil
+	})
+}
+
+// serveNext calls the router's middleware added by the next call to Use, or the matched
+// handler once all of the router's middleware has run
+func serveNext(next int, w http.ResponseWriter, r *http.Request) {
+	matched, ok := r.Context().Value(matchKey).(*matchContext)
+	if !ok {
+		// the middleware replaced the request's context with one that was not derived from it
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if next < len(matched.middleware) {
+		matched.middleware[next].ServeHTTP(w, r)
+	} else {
+		matched.handler.ServeHTTP(w, r)
+	}
+}
+
+// chain wraps a handler with middleware, so the first middleware is the outer most
+func chain(middleware []Middleware, handler http.Handler) http.Handler {
+	for index := len(middleware) - 1; index >= 0; index-- {
+		handler = middleware[index](handler)
+	}
+
+	return handler
+}
@@ -0,0 +1,188 @@
+package urlrouter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMiddleware(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	// records the order middleware ran in, along with the route it could see
+	recordMiddleware := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params := GetParams(r.Context())
+				w.Header().Add("X-Middleware", fmt.Sprintf("%s %s %v", name, params.Pattern(), params.Map()))
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	foundHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("found"))
+	}
+
+	router := New()
+	router.Use(recordMiddleware("router"))
+	router.HandleFunc("GET", "/static", foundHandler)
+	router.HandleFunc("GET", "/users/:id", foundHandler, WithMiddleware(recordMiddleware("route1"), recordMiddleware("route2")))
+
+	group := router.Group("/v1")
+	group.Use(recordMiddleware("group"))
+	group.HandleFunc("GET", "/users/:id", foundHandler, WithMiddleware(recordMiddleware("route")))
+
+	testServer := httptest.NewServer(router)
+	defer testServer.Close()
+
+	tests := []struct {
+		method     string
+		path       string
+		status     int
+		middleware []string
+	}{
+		{method: "GET", path: "/static", status: http.StatusOK, middleware: []string{
+			"router /static map[]",
+		}},
+		{method: "GET", path: "/users/42", status: http.StatusOK, middleware: []string{
+			"router /users/:id map[id:42]",
+			"route1 /users/:id map[id:42]",
+			"route2 /users/:id map[id:42]",
+		}},
+		{method: "HEAD", path: "/users/42", status: http.StatusOK, middleware: []string{
+			"router /users/:id map[id:42]",
+			"route1 /users/:id map[id:42]",
+			"route2 /users/:id map[id:42]",
+		}},
+		{method: "GET", path: "/v1/users/42", status: http.StatusOK, middleware: []string{
+			"router /v1/users/:id map[id:42]",
+			"group /v1/users/:id map[id:42]",
+			"route /v1/users/:id map[id:42]",
+		}},
+		{method: "GET", path: "/unknown", status: http.StatusNotFound, middleware: []string{
+			"router  map[]",
+		}},
+		{method: "POST", path: "/static", status: http.StatusMethodNotAllowed, middleware: []string{
+			"router  map[]",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("It runs the middleware for %s %s", test.method, test.path), func(t *testing.T) {
+			request, err := http.NewRequest(test.method, fmt.Sprintf("%s%s", testServer.URL, test.path), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(test.status))
+			g.Expect(resp.Header.Values("X-Middleware")).To(Equal(test.middleware))
+		})
+	}
+
+	t.Run("It lets middleware stop the request before the handler", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/admin/:id", foundHandler, WithMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if GetParams(r.Context()).MustString("id") != "root" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			})
+		}))
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		request, err := http.NewRequest("GET", fmt.Sprintf("%s/admin/guest", testServer.URL), nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(request)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+
+		request, err = http.NewRequest("GET", fmt.Sprintf("%s/admin/root", testServer.URL), nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		resp, err = client.Do(request)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		body, err := io.ReadAll(resp.Body)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(body)).To(Equal("found"))
+	})
+
+	t.Run("It builds the router middleware once and keeps its state across requests", func(t *testing.T) {
+		built := 0
+		countingMiddleware := func(next http.Handler) http.Handler {
+			built++
+			served := 0
+
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served++
+				w.Header().Set("X-Served", fmt.Sprint(served))
+				next.ServeHTTP(w, r)
+			})
+		}
+
+		router := New()
+		router.Use(countingMiddleware)
+		router.Use(recordMiddleware("second"))
+		router.HandleFunc("GET", "/users/:id", foundHandler)
+
+		for index := 1; index <= 3; index++ {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest("GET", "/users/42", nil))
+			g.Expect(recorder.Code).To(Equal(http.StatusOK))
+			g.Expect(recorder.Header().Get("X-Served")).To(Equal(fmt.Sprint(index)))
+			g.Expect(recorder.Header().Values("X-Middleware")).To(Equal([]string{"second /users/:id map[id:42]"}))
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/unknown", nil))
+		g.Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		g.Expect(recorder.Header().Get("X-Served")).To(Equal("4"))
+
+		router.Use(recordMiddleware("third"))
+		router.HandleFunc("GET", "/static", foundHandler)
+
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/static", nil))
+		g.Expect(recorder.Header().Get("X-Served")).To(Equal("5"))
+		g.Expect(recorder.Header().Values("X-Middleware")).To(Equal([]string{"second /static map[]", "third /static map[]"}))
+		g.Expect(built).To(Equal(1))
+	})
+
+	t.Run("It calls the handler matched by each router when routers are nested", func(t *testing.T) {
+		child := New()
+		child.Use(recordMiddleware("child"))
+		child.HandleFunc("GET", "/users/:id", foundHandler)
+
+		parent := New()
+		parent.Use(recordMiddleware("parent"))
+		parent.Group("").Mount("/api", child)
+
+		recorder := httptest.NewRecorder()
+		parent.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/users/42", nil))
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Body.String()).To(Equal("found"))
+
+		recorder = httptest.NewRecorder()
+		parent.ServeHTTP(recorder, httptest.NewRequest("OPTIONS", "/api/users/42", nil))
+		g.Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+		recorder = httptest.NewRecorder()
+		parent.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/unknown", nil))
+		g.Expect(recorder.Code).To(Equal(http.StatusNotFound))
+	})
+}
@@ -0,0 +1,491 @@
+package urlrouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIVersion is the version of the OpenAPI specification documents are generated for
+const openAPIVersion = "3.0.3"
+
+// Operation describes a route in the OpenAPI document generated by Router.OpenAPI
+type Operation struct {
+	ID          string   // unique operationId of the route
+	Summary     string   // short summary of what the route does
+	Description string   // longer description of the route
+	Tags        []string // tags used to group the route
+
+	// Params are the schemas of the named parameters. Named parameters without a schema
+	// are described by their constraint
+	Params map[string]*Schema
+
+	// Query are the query parameters of the route
+	Query []*OpenAPIParameter
+
+	// Request is a value with the type of the JSON request body, such as CreateUser{}, or
+	// the *Schema of the body
+	Request any
+
+	// RequestRequired is set when every request must have a body. Otherwise an empty body
+	// is accepted, and only a body that is sent is checked against the Request's schema
+	RequestRequired bool
+
+	// Responses are values with the type of the JSON response body for each status code, or
+	// the *Schema of the body. A nil value is a response without a body. Defaults to a 200
+	// response without a body
+	Responses map[int]any
+}
+
+// WithOperation describes the route in the router's OpenAPI document
+func WithOperation(operation Operation) RouteOption {
+	return func(newEndpoint *endpoint) {
+		newEndpoint.operation = &operation
+	}
+}
+
+// OpenAPIInfo is the info object of an OpenAPI document
+type OpenAPIInfo struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+// OpenAPIDocument is the subset of an OpenAPI 3 document that describes the router's routes
+type OpenAPIDocument struct {
+	OpenAPI string                      `json:"openapi" yaml:"openapi"`
+	Info    OpenAPIInfo                 `json:"info" yaml:"info"`
+	Paths   map[string]*OpenAPIPathItem `json:"paths" yaml:"paths"`
+}
+
+// OpenAPIPathItem holds the operations of a single path
+type OpenAPIPathItem struct {
+	Get     *OpenAPIOperation `json:"get,omitempty" yaml:"get,omitempty"`
+	Put     *OpenAPIOperation `json:"put,omitempty" yaml:"put,omitempty"`
+	Post    *OpenAPIOperation `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete  *OpenAPIOperation `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options *OpenAPIOperation `json:"options,omitempty" yaml:"options,omitempty"`
+	Head    *OpenAPIOperation `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch   *OpenAPIOperation `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Trace   *OpenAPIOperation `json:"trace,omitempty" yaml:"trace,omitempty"`
+
+	// Parameters are shared by every operation of the path
+	Parameters []*OpenAPIParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// OpenAPIOperation describes a single method of a path
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                      `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses" yaml:"responses"`
+}
+
+// OpenAPIParameter describes a path or query parameter of an operation
+type OpenAPIParameter struct {
+	Name        string  `json:"name" yaml:"name"`
+	In          string  `json:"in" yaml:"in"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody describes the body of a request
+type OpenAPIRequestBody struct {
+	Description string                       `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool                         `json:"required,omitempty" yaml:"required,omitempty"`
+	Content     map[string]*OpenAPIMediaType `json:"content" yaml:"content"`
+}
+
+// OpenAPIResponse describes the response for a status code
+type OpenAPIResponse struct {
+	Description string                       `json:"description" yaml:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// OpenAPIMediaType describes the body for a content type
+type OpenAPIMediaType struct {
+	Schema *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// JSON encodes the document as indented JSON
+func (document *OpenAPIDocument) JSON() ([]byte, error) {
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// YAML encodes the document as YAML
+func (document *OpenAPIDocument) YAML() ([]byte, error) {
+	return yaml.Marshal(document)
+}
+
+// operations returns a pointer to the operation field of each method
+func (pathItem *OpenAPIPathItem) operations() map[string]**OpenAPIOperation {
+	return map[string]**OpenAPIOperation{
+		http.MethodGet:     &pathItem.Get,
+		http.MethodPut:     &pathItem.Put,
+		http.MethodPost:    &pathItem.Post,
+		http.MethodDelete:  &pathItem.Delete,
+		http.MethodOptions: &pathItem.Options,
+		http.MethodHead:    &pathItem.Head,
+		http.MethodPatch:   &pathItem.Patch,
+		http.MethodTrace:   &pathItem.Trace,
+	}
+}
+
+// OpenAPI generates an OpenAPI 3 document for the routes registered on the router. Named
+// and catch all parameters, such as ":id" and "*filepath", become the path parameters
+// "{id}" and "{filepath}". Routes are described by WithOperation, and named parameters are
+// described by their constraint unless the operation includes their schema.
+//
+// Wildcards, mounted handlers and methods that OpenAPI does not support are left out. When
+// more than one pattern becomes the same path, such as "/users/:id<int>" and "/users/:id",
+// the pattern with the highest priority is used.
+func (router *Router) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	document := &OpenAPIDocument{OpenAPI: openAPIVersion, Info: info, Paths: map[string]*OpenAPIPathItem{}}
+
+	_ = router.table.Load().walk(func(foundEndpoint *endpoint) error {
+		if foundEndpoint.mounted || strings.HasSuffix(foundEndpoint.pattern, "/") {
+			return nil
+		}
+
+		path, parameters := router.openAPIPath(foundEndpoint)
+
+		pathItem, ok := document.Paths[path]
+		if !ok {
+			pathItem = &OpenAPIPathItem{}
+		}
+
+		operation, ok := pathItem.operations()[foundEndpoint.method]
+		if !ok || *operation != nil {
+			return nil
+		}
+
+		*operation = openAPIOperation(foundEndpoint.operation, parameters)
+		document.Paths[path] = pathItem
+		return nil
+	})
+
+	return document
+}
+
+// openAPIPath translates an endpoint's pattern into an OpenAPI path and its path parameters
+func (router *Router) openAPIPath(foundEndpoint *endpoint) (string, []*OpenAPIParameter) {
+	splitPaths, _ := splitPaths(foundEndpoint.pattern)
+
+	var params map[string]*Schema
+	if foundEndpoint.operation != nil {
+		params = foundEndpoint.operation.Params
+	}
+
+	builder := strings.Builder{}
+	var parameters []*OpenAPIParameter
+
+	for _, path := range splitPaths {
+		if !strings.HasPrefix(path, ":") && !strings.HasPrefix(path, "*") {
+			builder.WriteString(path)
+			continue
+		}
+
+		name, constraint := splitNamedParameter(path)
+		builder.WriteString("{" + name + "}")
+
+		parameter := &OpenAPIParameter{Name: name, In: "path", Required: true, Schema: params[name]}
+		if parameter.Schema == nil {
+			parameter.Schema = router.constraintSchema(constraint)
+		}
+
+		if strings.HasPrefix(path, "*") {
+			parameter.Description = "the rest of the path, which can include '/'"
+		}
+
+		parameters = append(parameters, parameter)
+	}
+
+	return builder.String(), parameters
+}
+
+// constraintSchema describes the values accepted by a named parameter's constraint
+func (router *Router) constraintSchema(constraint string) *Schema {
+	switch {
+	case strings.HasPrefix(constraint, "{"):
+		return &Schema{Type: "string", Pattern: fmt.Sprintf("^(?:%s)$", constraint[1:len(constraint)-1])}
+	case strings.HasPrefix(constraint, "<"):
+		constraintName := constraint[1 : len(constraint)-1]
+
+		// a constraint from the router's library replaces the default with the same name
+		if _, ok := router.constraints[constraintName]; ok {
+			return &Schema{Type: "string", Description: fmt.Sprintf("accepted by the %q constraint", constraintName)}
+		}
+
+		switch constraintName {
+		case "int":
+			return &Schema{Type: "integer"}
+		case "uuid":
+			return &Schema{Type: "string", Format: "uuid"}
+		case "alpha":
+			return &Schema{Type: "string", Pattern: "^[a-zA-Z]+$"}
+		case "alnum":
+			return &Schema{Type: "string", Pattern: "^[a-zA-Z0-9]+$"}
+		}
+	}
+
+	return &Schema{Type: "string"}
+}
+
+// openAPIOperation describes an endpoint's operation along with its path parameters
+func openAPIOperation(operation *Operation, parameters []*OpenAPIParameter) *OpenAPIOperation {
+	if operation == nil {
+		operation = &Operation{}
+	}
+
+	described := &OpenAPIOperation{
+		OperationID: operation.ID,
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		Tags:        operation.Tags,
+		Parameters:  parameters,
+		Responses:   map[string]*OpenAPIResponse{},
+	}
+
+	for _, query := range operation.Query {
+		parameter := *query
+		parameter.In = "query"
+		described.Parameters = append(described.Parameters, &parameter)
+	}
+
+	if operation.Request != nil {
+		described.RequestBody = &OpenAPIRequestBody{
+			Required: operation.RequestRequired,
+			Content:  map[string]*OpenAPIMediaType{"application/json": {Schema: bodySchema(operation.Request)}},
+		}
+	}
+
+	for status, body := range operation.Responses {
+		response := &OpenAPIResponse{Description: http.StatusText(status)}
+		if body != nil {
+			response.Content = map[string]*OpenAPIMediaType{"application/json": {Schema: bodySchema(body)}}
+		}
+
+		described.Responses[strconv.Itoa(status)] = response
+	}
+
+	if len(described.Responses) == 0 {
+		described.Responses["200"] = &OpenAPIResponse{Description: http.StatusText(http.StatusOK)}
+	}
+
+	return described
+}
+
+// bodySchema describes a body, which is either a value of the body's type or its *Schema
+func bodySchema(body any) *Schema {
+	if schema, ok := body.(*Schema); ok {
+		return schema
+	}
+
+	return SchemaOf(body)
+}
+
+// OpenAPIError reports the operations of an OpenAPI document that could not be loaded
+type OpenAPIError struct {
+	MissingHandlers []string      // operations without a handler, by operationId, or "METHOD path" without one
+	UnusedHandlers  []string      // operationIds of handlers without an operation
+	RouteErrors     []*RouteError // operations that could not be registered
+}
+
+func (e *OpenAPIError) Error() string {
+	var problems []string
+
+	if len(e.MissingHandlers) != 0 {
+		problems = append(problems, fmt.Sprintf("operations without a handler: %s", strings.Join(e.MissingHandlers, ", ")))
+	}
+
+	if len(e.UnusedHandlers) != 0 {
+		problems = append(problems, fmt.Sprintf("handlers without an operation: %s", strings.Join(e.UnusedHandlers, ", ")))
+	}
+
+	for _, routeErr := range e.RouteErrors {
+		problems = append(problems, routeErr.Error())
+	}
+
+	return fmt.Sprintf("openapi document could not be loaded: %s", strings.Join(problems, "; "))
+}
+
+// LoadOpenAPI registers a route for every operation of an OpenAPI 3 document, encoded as
+// JSON or YAML. Each operation is served by the handler for its operationId, and path
+// parameters such as "{id}" become named parameters. The operations are described with
+// WithOperation, so the parameter and body schemas are kept, and the options are applied
+// to every route.
+//
+// The routes are only registered when every operation has a handler, every handler has an
+// operation and every route can be registered. Otherwise an OpenAPIError reports all of
+// the problems. Routes that are already registered are never replaced. Schemas must be
+// written inline, since references to components are not resolved.
+func (router *Router) LoadOpenAPI(spec []byte, handlers map[string]http.HandlerFunc, options ...RouteOption) error {
+	document := &OpenAPIDocument{}
+	if err := yaml.Unmarshal(spec, document); err != nil {
+		return fmt.Errorf("failed to parse the openapi document: %w", err)
+	}
+
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		return fmt.Errorf("failed to parse the openapi document: unsupported openapi version %q", document.OpenAPI)
+	}
+
+	paths := make([]string, 0, len(document.Paths))
+	for path := range document.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	openAPIErr := &OpenAPIError{}
+	boundHandlers := map[string]bool{}
+	var newEndpoints []*endpoint
+
+	for _, path := range paths {
+		pathItem := document.Paths[path]
+
+		methods := make([]string, 0, len(pathItem.operations()))
+		for method, operation := range pathItem.operations() {
+			if *operation != nil {
+				methods = append(methods, method)
+			}
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			operation := *pathItem.operations()[method]
+
+			handler, ok := handlers[operation.OperationID]
+			if ok {
+				boundHandlers[operation.OperationID] = true
+			}
+
+			if handler == nil {
+				if operation.OperationID == "" {
+					openAPIErr.MissingHandlers = append(openAPIErr.MissingHandlers, method+" "+path)
+				} else {
+					openAPIErr.MissingHandlers = append(openAPIErr.MissingHandlers, operation.OperationID)
+				}
+				continue
+			}
+
+			pattern, err := routerPattern(path)
+			if err != nil {
+				openAPIErr.RouteErrors = append(openAPIErr.RouteErrors, &RouteError{Method: method, Pattern: path, Err: err})
+				continue
+			}
+
+			routeOptions := append([]RouteOption{WithOperation(loadedOperation(pathItem, operation))}, options...)
+			newEndpoints = append(newEndpoints, router.newEndpoint(method, pattern, handler, routeOptions))
+		}
+	}
+
+	for operationID := range handlers {
+		if !boundHandlers[operationID] {
+			openAPIErr.UnusedHandlers = append(openAPIErr.UnusedHandlers, operationID)
+		}
+	}
+	sort.Strings(openAPIErr.UnusedHandlers)
+
+	return router.update(func(updated *table) error {
+		for _, newEndpoint := range newEndpoints {
+			if err := updated.add(newEndpoint, router.constraints, false, router.strict); err != nil {
+				openAPIErr.RouteErrors = append(openAPIErr.RouteErrors, err.(*RouteError))
+			}
+		}
+
+		if len(openAPIErr.MissingHandlers) != 0 || len(openAPIErr.UnusedHandlers) != 0 || len(openAPIErr.RouteErrors) != 0 {
+			return openAPIErr
+		}
+
+		return nil
+	})
+}
+
+// routerPattern translates an OpenAPI path into a pattern, so "/users/{id}" becomes "/users/:id".
+// Paths that Add would reject, such as paths that do not start with '/', are rejected the same way
+func routerPattern(path string) (string, error) {
+	segments := strings.Split(path, "/")
+
+	for index, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") && strings.Count(segment, "{") == 1:
+			segments[index] = ":" + segment[1:len(segment)-1]
+		case strings.ContainsAny(segment, "{}"):
+			return "", fmt.Errorf("%w: path parameter %q must be a whole path segment", ErrInvalidPattern, segment)
+		}
+	}
+
+	pattern := strings.Join(segments, "/")
+	if err := validPattern(pattern); err != nil {
+		return "", err
+	}
+
+	return pattern, nil
+}
+
+// loadedOperation describes a route loaded from an OpenAPI document. The operation's
+// parameters replace the path item's parameters with the same name
+func loadedOperation(pathItem *OpenAPIPathItem, described *OpenAPIOperation) Operation {
+	operation := Operation{
+		ID:          described.OperationID,
+		Summary:     described.Summary,
+		Description: described.Description,
+		Tags:        described.Tags,
+	}
+
+	parameters := map[string]*OpenAPIParameter{}
+	var order []string
+	for _, parameter := range append(append([]*OpenAPIParameter{}, pathItem.Parameters...), described.Parameters...) {
+		key := parameter.In + " " + parameter.Name
+		if _, ok := parameters[key]; !ok {
+			order = append(order, key)
+		}
+		parameters[key] = parameter
+	}
+
+	for _, key := range order {
+		switch parameter := parameters[key]; parameter.In {
+		case "path":
+			if operation.Params == nil {
+				operation.Params = map[string]*Schema{}
+			}
+			operation.Params[parameter.Name] = parameter.Schema
+		case "query":
+			operation.Query = append(operation.Query, parameter)
+		}
+	}
+
+	if described.RequestBody != nil {
+		if mediaType, ok := described.RequestBody.Content["application/json"]; ok && mediaType.Schema != nil {
+			operation.Request = mediaType.Schema
+			operation.RequestRequired = described.RequestBody.Required
+		}
+	}
+
+	for status, response := range described.Responses {
+		code, err := strconv.Atoi(status)
+		if err != nil {
+			// ranges such as "2XX" and the default response have no single status code
+			continue
+		}
+
+		if operation.Responses == nil {
+			operation.Responses = map[int]any{}
+		}
+
+		operation.Responses[code] = nil
+		if mediaType, ok := response.Content["application/json"]; ok && mediaType.Schema != nil {
+			operation.Responses[code] = mediaType.Schema
+		}
+	}
+
+	return operation
+}
@@ -0,0 +1,321 @@
+package urlrouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRouter_OpenAPI(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	foundHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	type createUser struct {
+		Name string `json:"name"`
+	}
+
+	type user struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+
+	setupRouter := func() *Router {
+		router := New(WithConstraints(Constraints{"even": func(string) bool { return true }}))
+		router.HandleFunc("POST", "/users", foundHandler, WithOperation(Operation{
+			ID:              "createUser",
+			Summary:         "Create a user",
+			Tags:            []string{"users"},
+			Request:         createUser{},
+			RequestRequired: true,
+			Responses:       map[int]any{http.StatusCreated: user{}, http.StatusBadRequest: nil},
+		}))
+		router.HandleFunc("GET", "/users/:id<int>", foundHandler, WithOperation(Operation{
+			ID:        "getUser",
+			Responses: map[int]any{http.StatusOK: user{}},
+		}))
+		router.HandleFunc("GET", "/users/:id", foundHandler)
+		router.HandleFunc("DELETE", "/users/:id<uuid>", foundHandler, WithOperation(Operation{
+			ID:     "deleteUser",
+			Params: map[string]*Schema{"id": {Type: "string", Description: "id of the user"}},
+		}))
+		router.HandleFunc("GET", "/orders/:code{[A-Z]+}/pages/:page<even>", foundHandler)
+		router.HandleFunc("GET", "/files/*filepath", foundHandler)
+		router.HandleFunc("GET", "/static/", foundHandler)
+		router.HandleFunc("M-SEARCH", "/devices", foundHandler)
+		router.Mount("/legacy", http.HandlerFunc(foundHandler))
+		return router
+	}
+
+	t.Run("It translates the patterns into paths", func(t *testing.T) {
+		document := setupRouter().OpenAPI(OpenAPIInfo{Title: "users", Version: "1.0.0"})
+
+		g.Expect(document.OpenAPI).To(Equal("3.0.3"))
+		g.Expect(document.Info).To(Equal(OpenAPIInfo{Title: "users", Version: "1.0.0"}))
+		g.Expect(document.Paths).To(HaveLen(4))
+		g.Expect(document.Paths).To(HaveKey("/users"))
+		g.Expect(document.Paths).To(HaveKey("/users/{id}"))
+		g.Expect(document.Paths).To(HaveKey("/orders/{code}/pages/{page}"))
+		g.Expect(document.Paths).To(HaveKey("/files/{filepath}"))
+	})
+
+	t.Run("It describes the operations", func(t *testing.T) {
+		document := setupRouter().OpenAPI(OpenAPIInfo{Title: "users", Version: "1.0.0"})
+
+		create := document.Paths["/users"].Post
+		g.Expect(create.OperationID).To(Equal("createUser"))
+		g.Expect(create.Summary).To(Equal("Create a user"))
+		g.Expect(create.Tags).To(Equal([]string{"users"}))
+		g.Expect(create.Parameters).To(BeEmpty())
+		g.Expect(create.RequestBody.Required).To(BeTrue())
+		g.Expect(create.RequestBody.Content["application/json"].Schema).To(Equal(SchemaOf(createUser{})))
+		g.Expect(create.Responses).To(HaveLen(2))
+		g.Expect(create.Responses["201"].Description).To(Equal("Created"))
+		g.Expect(create.Responses["201"].Content["application/json"].Schema).To(Equal(SchemaOf(user{})))
+		g.Expect(create.Responses["400"]).To(Equal(&OpenAPIResponse{Description: "Bad Request"}))
+
+		// the constrained pattern has priority over "/users/:id"
+		get := document.Paths["/users/{id}"].Get
+		g.Expect(get.OperationID).To(Equal("getUser"))
+		g.Expect(get.Parameters).To(Equal([]*OpenAPIParameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer"}}}))
+
+		remove := document.Paths["/users/{id}"].Delete
+		g.Expect(remove.Parameters[0].Schema).To(Equal(&Schema{Type: "string", Description: "id of the user"}))
+		g.Expect(remove.Responses).To(Equal(map[string]*OpenAPIResponse{"200": {Description: "OK"}}))
+
+		orders := document.Paths["/orders/{code}/pages/{page}"].Get
+		g.Expect(orders.OperationID).To(BeEmpty())
+		g.Expect(orders.Parameters[0].Schema).To(Equal(&Schema{Type: "string", Pattern: "^(?:[A-Z]+)$"}))
+		g.Expect(orders.Parameters[1].Schema.Type).To(Equal("string"))
+		g.Expect(orders.Parameters[1].Schema.Description).To(ContainSubstring("even"))
+
+		files := document.Paths["/files/{filepath}"].Get
+		g.Expect(files.Parameters[0].Name).To(Equal("filepath"))
+		g.Expect(files.Parameters[0].Description).ToNot(BeEmpty())
+	})
+
+	t.Run("It encodes the document as JSON and YAML", func(t *testing.T) {
+		document := setupRouter().OpenAPI(OpenAPIInfo{Title: "users", Version: "1.0.0"})
+
+		encodedJSON, err := document.JSON()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(encodedJSON)).To(ContainSubstring(`"openapi": "3.0.3"`))
+		g.Expect(string(encodedJSON)).To(ContainSubstring(`"operationId": "createUser"`))
+
+		decodedJSON := &OpenAPIDocument{}
+		g.Expect(json.Unmarshal(encodedJSON, decodedJSON)).To(Succeed())
+		g.Expect(decodedJSON.Paths["/users"].Post.OperationID).To(Equal("createUser"))
+
+		encodedYAML, err := document.YAML()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(encodedYAML)).To(ContainSubstring("openapi: 3.0.3"))
+		g.Expect(string(encodedYAML)).To(ContainSubstring("/users/{id}:"))
+
+		decodedYAML := &OpenAPIDocument{}
+		g.Expect(yaml.Unmarshal(encodedYAML, decodedYAML)).To(Succeed())
+		g.Expect(decodedYAML).To(Equal(decodedJSON))
+	})
+}
+
+func TestRouter_LoadOpenAPI(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	spec := []byte(`
+openapi: 3.0.3
+info:
+  title: users
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: OK
+    post:
+      operationId: createUser
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              schema:
+                type: object
+        default:
+          description: Error
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: string
+    get:
+      operationId: getUser
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: OK
+    delete:
+      operationId: deleteUser
+      responses:
+        "204":
+          description: No Content
+`)
+
+	patternHandler := func(w http.ResponseWriter, r *http.Request) {
+		info, _ := GetRouteInfo(r.Context())
+		_, _ = w.Write([]byte(info.Pattern + " " + info.Params.MustString("id")))
+	}
+
+	foundHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	handlers := func() map[string]http.HandlerFunc {
+		return map[string]http.HandlerFunc{
+			"listUsers":  foundHandler,
+			"createUser": foundHandler,
+			"getUser":    patternHandler,
+			"deleteUser": patternHandler,
+		}
+	}
+
+	t.Run("It registers every operation with its handler", func(t *testing.T) {
+		router := New()
+		g.Expect(router.LoadOpenAPI(spec, handlers())).To(Succeed())
+
+		var walked []string
+		g.Expect(router.Walk(func(method, pattern string, handler http.Handler) error {
+			walked = append(walked, method+" "+pattern)
+			return nil
+		})).To(Succeed())
+		g.Expect(walked).To(Equal([]string{"DELETE /users/:id", "GET /users", "GET /users/:id", "POST /users"}))
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/users/42", nil))
+		g.Expect(recorder.Body.String()).To(Equal("/users/:id 42"))
+	})
+
+	t.Run("It keeps the schemas of the operations", func(t *testing.T) {
+		router := New()
+		g.Expect(router.LoadOpenAPI(spec, handlers())).To(Succeed())
+
+		document := router.OpenAPI(OpenAPIInfo{Title: "users", Version: "1.0.0"})
+		g.Expect(document.Paths["/users/{id}"].Get.Parameters[0].Schema).To(Equal(&Schema{Type: "integer"}))
+		g.Expect(document.Paths["/users/{id}"].Delete.Parameters[0].Schema).To(Equal(&Schema{Type: "string"}))
+		g.Expect(document.Paths["/users"].Get.Parameters).To(Equal([]*OpenAPIParameter{{Name: "limit", In: "query", Schema: &Schema{Type: "integer"}}}))
+		g.Expect(document.Paths["/users"].Post.RequestBody.Required).To(BeTrue())
+		g.Expect(document.Paths["/users"].Post.RequestBody.Content["application/json"].Schema.Required).To(Equal([]string{"name"}))
+		g.Expect(document.Paths["/users"].Post.Responses).To(HaveLen(1))
+		g.Expect(document.Paths["/users"].Post.Responses["201"].Content["application/json"].Schema).To(Equal(&Schema{Type: "object"}))
+	})
+
+	t.Run("It applies the options to every route", func(t *testing.T) {
+		router := New()
+		g.Expect(router.LoadOpenAPI(spec, handlers(), WithMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Middleware", "loaded")
+				next.ServeHTTP(w, r)
+			})
+		}))).To(Succeed())
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/users", nil))
+		g.Expect(recorder.Header().Get("X-Middleware")).To(Equal("loaded"))
+	})
+
+	t.Run("It reports missing and unused handlers without registering any routes", func(t *testing.T) {
+		router := New()
+		loadHandlers := handlers()
+		delete(loadHandlers, "deleteUser")
+		loadHandlers["getUser"] = nil
+		loadHandlers["updateUser"] = foundHandler
+		loadHandlers["archiveUser"] = foundHandler
+
+		err := router.LoadOpenAPI(spec, loadHandlers)
+		g.Expect(err).To(HaveOccurred())
+
+		openAPIErr, ok := err.(*OpenAPIError)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(openAPIErr.MissingHandlers).To(Equal([]string{"deleteUser", "getUser"}))
+		g.Expect(openAPIErr.UnusedHandlers).To(Equal([]string{"archiveUser", "updateUser"}))
+		g.Expect(openAPIErr.RouteErrors).To(BeEmpty())
+		g.Expect(err.Error()).To(ContainSubstring("deleteUser"))
+		g.Expect(err.Error()).To(ContainSubstring("updateUser"))
+
+		g.Expect(router.Routes()).To(BeEmpty())
+	})
+
+	t.Run("It reports operations without an operationId", func(t *testing.T) {
+		router := New()
+		err := router.LoadOpenAPI([]byte(`{"openapi": "3.0.0", "info": {"title": "t", "version": "1"}, "paths": {"/health": {"get": {"responses": {"200": {"description": "OK"}}}}}}`), nil)
+
+		openAPIErr, ok := err.(*OpenAPIError)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(openAPIErr.MissingHandlers).To(Equal([]string{"GET /health"}))
+	})
+
+	t.Run("It reports routes that can't be registered", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users", foundHandler)
+
+		err := router.LoadOpenAPI([]byte(`
+openapi: 3.1.0
+info: {title: t, version: "1"}
+paths:
+  /users:
+    get: {operationId: listUsers}
+  /files/{name}.json:
+    get: {operationId: getFile}
+  users/{id}:
+    get: {operationId: getUser}
+  /teams//{id}:
+    get: {operationId: getTeam}
+`), map[string]http.HandlerFunc{"listUsers": foundHandler, "getFile": foundHandler, "getUser": foundHandler, "getTeam": foundHandler})
+
+		openAPIErr, ok := err.(*OpenAPIError)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(openAPIErr.RouteErrors).To(HaveLen(4))
+		g.Expect(openAPIErr.RouteErrors[0].Pattern).To(Equal("/files/{name}.json"))
+		g.Expect(openAPIErr.RouteErrors[0]).To(MatchError(ErrInvalidPattern))
+		g.Expect(openAPIErr.RouteErrors[1].Pattern).To(Equal("/teams//{id}"))
+		g.Expect(openAPIErr.RouteErrors[1]).To(MatchError(ErrInvalidPattern))
+		g.Expect(openAPIErr.RouteErrors[2].Pattern).To(Equal("users/{id}"))
+		g.Expect(openAPIErr.RouteErrors[2]).To(MatchError(ErrInvalidPattern))
+		g.Expect(openAPIErr.RouteErrors[3]).To(MatchError(ErrDuplicateRoute))
+		g.Expect(router.Routes()).To(HaveLen(1))
+	})
+
+	t.Run("It errors on documents that are not OpenAPI 3", func(t *testing.T) {
+		router := New()
+		g.Expect(router.LoadOpenAPI([]byte(`swagger: "2.0"`), nil)).To(MatchError(ContainSubstring("unsupported openapi version")))
+		g.Expect(router.LoadOpenAPI([]byte(`{`), nil)).To(MatchError(ContainSubstring("failed to parse")))
+	})
+}
@@ -0,0 +1,359 @@
+package urlrouter
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrMissingParam is returned when a named parameter is not set on the matched route
+	ErrMissingParam = errors.New("named parameter is not set")
+
+	// ErrUnknownParam is returned when building a url with a named parameter the route does not use
+	ErrUnknownParam = errors.New("named parameter is not used by the route")
+
+	// ErrDuplicateParam is returned when building a url with the same named parameter more than once
+	ErrDuplicateParam = errors.New("named parameter is set more than once")
+)
+
+// ParamError describes a named parameter that could not be read or converted
+type ParamError struct {
+	Name    string // name of the named parameter
+	Pattern string // pattern of the route that matched the request
+	Value   string // raw value of the named parameter
+	Type    string // type the value was converted to
+	Err     error
+}
+
+func (e *ParamError) Error() string {
+	route := ""
+	if e.Pattern != "" {
+		route = fmt.Sprintf(" of route %q", e.Pattern)
+	}
+
+	switch {
+	case errors.Is(e.Err, ErrMissingParam):
+		return fmt.Sprintf("named parameter %q%s is not set", e.Name, route)
+	case errors.Is(e.Err, ErrUnknownParam):
+		return fmt.Sprintf("named parameter %q is not used by route %q", e.Name, e.Pattern)
+	case errors.Is(e.Err, ErrDuplicateParam):
+		return fmt.Sprintf("named parameter %q%s is set more than once", e.Name, route)
+	}
+
+	return fmt.Sprintf("named parameter %q%s is not a valid %s: %q", e.Name, route, e.Type, e.Value)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// Params are the named parameters of the route that matched a request
+type Params struct {
+	pattern string
+	names   []string
+	values  []string
+}
+
+// GetParams returns the named parameters of the route that matched the request
+func GetParams(ctx context.Context) Params {
+	switch value := ctx.Value(NAMED_PAAMTERS).(type) {
+	case *RouteInfo:
+		return value.Params
+	case map[string]string:
+		params := Params{}
+		for name, paramValue := range value {
+			params.names = append(params.names, name)
+			params.values = append(params.values, paramValue)
+		}
+
+		return params
+	default:
+		return Params{}
+	}
+}
+
+// Pattern returns the pattern of the route that matched the request
+func (p Params) Pattern() string {
+	return p.pattern
+}
+
+// Len returns the number of named parameters
+func (p Params) Len() int {
+	return len(p.names)
+}
+
+// Get returns the value of a named parameter and if it was set
+func (p Params) Get(name string) (string, bool) {
+	for index, paramName := range p.names {
+		if paramName == name {
+			return p.values[index], true
+		}
+	}
+
+	return "", false
+}
+
+// Map returns a copy of the named parameters. This is nil when there are no named parameters
+func (p Params) Map() map[string]string {
+	if len(p.names) == 0 {
+		return nil
+	}
+
+	params := make(map[string]string, len(p.names))
+	for index, name := range p.names {
+		params[name] = p.values[index]
+	}
+
+	return params
+}
+
+// without returns a copy of the named parameters without the name
+func (p Params) without(name string) Params {
+	params := Params{pattern: p.pattern}
+	for index, paramName := range p.names {
+		if paramName != name {
+			params.names = append(params.names, paramName)
+			params.values = append(params.values, p.values[index])
+		}
+	}
+
+	return params
+}
+
+// String returns the value of a named parameter, or an error if it is not set
+func (p Params) String(name string) (string, error) {
+	if value, ok := p.Get(name); ok {
+		return value, nil
+	}
+
+	return "", &ParamError{Name: name, Pattern: p.pattern, Type: "string", Err: ErrMissingParam}
+}
+
+// Int returns the value of a named parameter as an int
+func (p Params) Int(name string) (int, error) {
+	value, err := p.Int64(name)
+	if err != nil {
+		return 0, p.withType(err, "int")
+	}
+
+	if int64(int(value)) != value {
+		return 0, p.invalid(name, "int", strconv.ErrRange)
+	}
+
+	return int(value), nil
+}
+
+// Int64 returns the value of a named parameter as an int64
+func (p Params) Int64(name string) (int64, error) {
+	value, err := p.String(name)
+	if err != nil {
+		return 0, p.withType(err, "int64")
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, p.invalid(name, "int64", err)
+	}
+
+	return number, nil
+}
+
+// Float64 returns the value of a named parameter as a float64
+func (p Params) Float64(name string) (float64, error) {
+	value, err := p.String(name)
+	if err != nil {
+		return 0, p.withType(err, "float64")
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, p.invalid(name, "float64", err)
+	}
+
+	return number, nil
+}
+
+// Bool returns the value of a named parameter as a bool. Any value accepted by
+// strconv.ParseBool is valid
+func (p Params) Bool(name string) (bool, error) {
+	value, err := p.String(name)
+	if err != nil {
+		return false, p.withType(err, "bool")
+	}
+
+	boolean, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, p.invalid(name, "bool", err)
+	}
+
+	return boolean, nil
+}
+
+// UUID returns the value of a named parameter, validating that it is formatted as a UUID
+func (p Params) UUID(name string) (string, error) {
+	value, err := p.String(name)
+	if err != nil {
+		return "", p.withType(err, "uuid")
+	}
+
+	if !defaultConstraints["uuid"](value) {
+		return "", p.invalid(name, "uuid", nil)
+	}
+
+	return value, nil
+}
+
+// Time returns the value of a named parameter parsed with the time layout
+func (p Params) Time(name, layout string) (time.Time, error) {
+	value, err := p.String(name)
+	if err != nil {
+		return time.Time{}, p.withType(err, "time")
+	}
+
+	parsed, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, p.invalid(name, "time", err)
+	}
+
+	return parsed, nil
+}
+
+// MustString is the same as String, but panics on an error
+func (p Params) MustString(name string) string {
+	return must(p.String(name))
+}
+
+// MustInt is the same as Int, but panics on an error
+func (p Params) MustInt(name string) int {
+	return must(p.Int(name))
+}
+
+// MustInt64 is the same as Int64, but panics on an error
+func (p Params) MustInt64(name string) int64 {
+	return must(p.Int64(name))
+}
+
+// MustFloat64 is the same as Float64, but panics on an error
+func (p Params) MustFloat64(name string) float64 {
+	return must(p.Float64(name))
+}
+
+// MustBool is the same as Bool, but panics on an error
+func (p Params) MustBool(name string) bool {
+	return must(p.Bool(name))
+}
+
+// MustUUID is the same as UUID, but panics on an error
+func (p Params) MustUUID(name string) string {
+	return must(p.UUID(name))
+}
+
+// MustTime is the same as Time, but panics on an error
+func (p Params) MustTime(name, layout string) time.Time {
+	return must(p.Time(name, layout))
+}
+
+func must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+func (p Params) invalid(name, paramType string, err error) error {
+	value, _ := p.Get(name)
+	return &ParamError{Name: name, Pattern: p.pattern, Value: value, Type: paramType, Err: err}
+}
+
+func (p Params) withType(err error, paramType string) error {
+	var paramErr *ParamError
+	if errors.As(err, &paramErr) {
+		paramErr.Type = paramType
+	}
+
+	return err
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Bind sets the fields of a struct pointer from the named parameters. Fields are selected
+// with the `param:"name"` tag. Supported field types are strings, bools, ints, uints, floats
+// and any type that implements encoding.TextUnmarshaler, such as time.Time
+func (p Params) Bind(v any) error {
+	structValue := reflect.ValueOf(v)
+	if structValue.Kind() != reflect.Pointer || structValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind requires a pointer to a struct, received %T", v)
+	}
+
+	structValue = structValue.Elem()
+	structType := structValue.Type()
+
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+
+		name, ok := field.Tag.Lookup("param")
+		if !ok || name == "-" {
+			continue
+		}
+
+		if !field.IsExported() {
+			return fmt.Errorf("bind field %s with the param tag %q is not exported", field.Name, name)
+		}
+
+		value, err := p.String(name)
+		if err != nil {
+			return p.withType(err, field.Type.String())
+		}
+
+		if err := setField(structValue.Field(index), value); err != nil {
+			return &ParamError{Name: name, Pattern: p.pattern, Value: value, Type: field.Type.String(), Err: err}
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	if reflect.PtrTo(field.Type()).Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolean)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(number)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(number)
+	case reflect.Float32, reflect.Float64:
+		number, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(number)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
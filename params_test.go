@@ -0,0 +1,197 @@
+package urlrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParams_Accessors(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	params := Params{
+		pattern: "/users/:id/:active/:uuid/:day/:ratio/:name",
+		names:   []string{"id", "active", "uuid", "day", "ratio", "name"},
+		values:  []string{"42", "true", "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "2023-10-16", "0.5", "bob"},
+	}
+
+	t.Run("It converts valid values", func(t *testing.T) {
+		g.Expect(params.Pattern()).To(Equal("/users/:id/:active/:uuid/:day/:ratio/:name"))
+		g.Expect(params.Len()).To(Equal(6))
+		g.Expect(params.MustString("name")).To(Equal("bob"))
+		g.Expect(params.MustInt("id")).To(Equal(42))
+		g.Expect(params.MustInt64("id")).To(Equal(int64(42)))
+		g.Expect(params.MustFloat64("ratio")).To(Equal(0.5))
+		g.Expect(params.MustBool("active")).To(BeTrue())
+		g.Expect(params.MustUUID("uuid")).To(Equal("6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+		g.Expect(params.MustTime("day", "2006-01-02")).To(Equal(time.Date(2023, 10, 16, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("It returns errors that name the parameter and the route", func(t *testing.T) {
+		_, err := params.Int("name")
+		g.Expect(err).To(MatchError(`named parameter "name" of route "/users/:id/:active/:uuid/:day/:ratio/:name" is not a valid int: "bob"`))
+		g.Expect(errors.Is(err, strconv.ErrSyntax)).To(BeTrue())
+
+		_, err = params.UUID("id")
+		g.Expect(err).To(MatchError(`named parameter "id" of route "/users/:id/:active/:uuid/:day/:ratio/:name" is not a valid uuid: "42"`))
+
+		_, err = params.Bool("missing")
+		g.Expect(err).To(MatchError(`named parameter "missing" of route "/users/:id/:active/:uuid/:day/:ratio/:name" is not set`))
+		g.Expect(errors.Is(err, ErrMissingParam)).To(BeTrue())
+
+		var paramErr *ParamError
+		g.Expect(errors.As(err, &paramErr)).To(BeTrue())
+		g.Expect(paramErr.Name).To(Equal("missing"))
+		g.Expect(paramErr.Type).To(Equal("bool"))
+	})
+
+	t.Run("It panics with the Must accessors on an error", func(t *testing.T) {
+		g.Expect(func() { params.MustInt("name") }).To(Panic())
+		g.Expect(func() { params.MustTime("day", time.RFC3339) }).To(Panic())
+	})
+
+	t.Run("It returns an empty set of params when nothing was matched", func(t *testing.T) {
+		empty := GetParams(httptest.NewRequest("GET", "/", nil).Context())
+		g.Expect(empty.Len()).To(Equal(0))
+		g.Expect(empty.Map()).To(BeNil())
+
+		_, err := empty.String("id")
+		g.Expect(err).To(MatchError(`named parameter "id" is not set`))
+	})
+}
+
+func TestParams_Bind(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	params := Params{
+		pattern: "/users/:id/:active/:day/:name",
+		names:   []string{"id", "active", "day", "name"},
+		values:  []string{"42", "true", "2023-10-16T00:00:00Z", "bob"},
+	}
+
+	t.Run("It binds the tagged fields", func(t *testing.T) {
+		var target struct {
+			ID      uint16    `param:"id"`
+			Active  bool      `param:"active"`
+			Day     time.Time `param:"day"`
+			Name    string    `param:"name"`
+			Ignored string
+		}
+
+		g.Expect(params.Bind(&target)).ToNot(HaveOccurred())
+		g.Expect(target.ID).To(Equal(uint16(42)))
+		g.Expect(target.Active).To(BeTrue())
+		g.Expect(target.Day).To(Equal(time.Date(2023, 10, 16, 0, 0, 0, 0, time.UTC)))
+		g.Expect(target.Name).To(Equal("bob"))
+		g.Expect(target.Ignored).To(BeEmpty())
+	})
+
+	t.Run("It returns an error for values that cannot be converted", func(t *testing.T) {
+		var target struct {
+			Name int `param:"name"`
+		}
+
+		g.Expect(params.Bind(&target)).To(MatchError(`named parameter "name" of route "/users/:id/:active/:day/:name" is not a valid int: "bob"`))
+	})
+
+	t.Run("It returns an error for missing parameters", func(t *testing.T) {
+		var target struct {
+			Missing string `param:"missing"`
+		}
+
+		g.Expect(params.Bind(&target)).To(MatchError(ErrMissingParam))
+	})
+
+	t.Run("It returns an error when not given a pointer to a struct", func(t *testing.T) {
+		var target struct{}
+		g.Expect(params.Bind(target)).To(HaveOccurred())
+	})
+}
+
+func TestParams_FromRequests(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	var params Params
+	router := New()
+	router.HandleFunc("GET", "/users/:id<int>/posts/:post", func(w http.ResponseWriter, r *http.Request) {
+		params = GetParams(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testServer := httptest.NewServer(router)
+	defer testServer.Close()
+
+	request, err := http.NewRequest("GET", fmt.Sprintf("%s/users/42/posts/hello", testServer.URL), nil)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	resp, err := client.Do(request)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	g.Expect(params.Pattern()).To(Equal("/users/:id<int>/posts/:post"))
+	g.Expect(params.MustInt("id")).To(Equal(42))
+	g.Expect(params.MustString("post")).To(Equal("hello"))
+}
+
+func TestParams_ParentRouters(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var params Params
+	router := New()
+	router.HandleFunc("GET", "/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		params = GetParams(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	router.HandleFunc("GET", "/static", func(w http.ResponseWriter, r *http.Request) {
+		params = GetParams(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("It keeps the parent's parameters without modifying them", func(t *testing.T) {
+		parent := Params{pattern: "/:tenant/", names: []string{"tenant", "id"}, values: []string{"acme", "parent_id"}}
+		request := httptest.NewRequest("GET", "/users/42", nil)
+		request = request.WithContext(context.WithValue(request.Context(), NAMED_PAAMTERS, &RouteInfo{Params: parent}))
+
+		router.ServeHTTP(httptest.NewRecorder(), request)
+		g.Expect(params.Map()).To(Equal(map[string]string{"tenant": "acme", "id": "42"}))
+		g.Expect(params.Pattern()).To(Equal("/users/:id"))
+		g.Expect(parent.Map()).To(Equal(map[string]string{"tenant": "acme", "id": "parent_id"}))
+		g.Expect(GetParams(request.Context()).Map()).To(Equal(map[string]string{"tenant": "acme", "id": "parent_id"}))
+	})
+
+	t.Run("It keeps a parent's map of parameters without modifying it", func(t *testing.T) {
+		parent := map[string]string{"tenant": "acme"}
+		request := httptest.NewRequest("GET", "/users/42", nil)
+		request = request.WithContext(context.WithValue(request.Context(), NAMED_PAAMTERS, parent))
+
+		router.ServeHTTP(httptest.NewRecorder(), request)
+		g.Expect(params.Map()).To(Equal(map[string]string{"tenant": "acme", "id": "42"}))
+		g.Expect(parent).To(Equal(map[string]string{"tenant": "acme"}))
+	})
+
+	t.Run("It keeps the parent's parameters for routes without named parameters", func(t *testing.T) {
+		parent := Params{pattern: "/:tenant/", names: []string{"tenant"}, values: []string{"acme"}}
+		request := httptest.NewRequest("GET", "/static", nil)
+		request = request.WithContext(context.WithValue(request.Context(), NAMED_PAAMTERS, &RouteInfo{Params: parent}))
+
+		router.ServeHTTP(httptest.NewRecorder(), request)
+		g.Expect(params.Map()).To(Equal(map[string]string{"tenant": "acme"}))
+	})
+
+	t.Run("It does not share the parameters between requests", func(t *testing.T) {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+		first := params
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/2", nil))
+		g.Expect(first.Map()).To(Equal(map[string]string{"id": "1"}))
+		g.Expect(params.Map()).To(Equal(map[string]string{"id": "2"}))
+	})
+}
@@ -2,51 +2,115 @@ package urlrouter
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"unicode/utf8"
 )
 
 type urlNamedParameter string
 
 const (
+	// NAMED_PAAMTERS is the context key for the RouteInfo of the route that matched a request
 	NAMED_PAAMTERS urlNamedParameter = "urlrouter_named_parameters"
+	PARTIAL_MATCH  urlNamedParameter = "urlrouter_partial_match"
+	MATCHER_STATUS urlNamedParameter = "urlrouter_matcher_status"
+
+	// matchKey is the context key for the matchContext of a request
+	matchKey urlNamedParameter = "urlrouter_match"
 )
 
-func GetNamedParamters(ctx context.Context) map[string]string {
-	if value := ctx.Value(NAMED_PAAMTERS); value != nil {
-		return value.(map[string]string)
-	}
+// matchContext is attached to a request once it is matched to a route. It carries the
+// RouteInfo, along with the matched handler and the router's middleware when the router has
+// middleware, in a single context so attaching them only copies the request once
+type matchContext struct {
+	context.Context
 
-	return nil
+	info       *RouteInfo
+	handler    http.Handler
+	middleware []http.Handler
 }
 
-func setNamedParameter(name, value string, r *http.Request) *http.Request {
-	values := r.Context().Value(NAMED_PAAMTERS)
+func (ctx *matchContext) Value(key any) any {
+	switch {
+	case key == matchKey:
+		return ctx
+	case key == NAMED_PAAMTERS && ctx.info != nil:
+		return ctx.info
+	default:
+		return ctx.Context.Value(key)
+	}
+}
+
+// GetNamedParamters returns a copy of the named parameters for the route that matched
+// the request. This is nil when the route has no named parameters
+func GetNamedParamters(ctx context.Context) map[string]string {
+	switch value := ctx.Value(NAMED_PAAMTERS).(type) {
+	case *RouteInfo:
+		return value.Params.Map()
+	case map[string]string:
+		return value
+	default:
+		return nil
+	}
+}
 
-	// adding the first named parameter
-	if values == nil {
-		return r.WithContext(context.WithValue(r.Context(), NAMED_PAAMTERS, map[string]string{name: value}))
+// GetPartialMatch returns the pattern prefix of the deepest route that matched a request
+// before routing failed. This is only set for the NotFound and MethodNotAllowed handlers
+func GetPartialMatch(ctx context.Context) string {
+	if value := ctx.Value(PARTIAL_MATCH); value != nil {
+		return value.(string)
 	}
 
-	// insert the new parameter
-	values.(map[string]string)[name] = value
-	return r
+	return ""
 }
 
 func trimPaths(path string) string {
 	path = strings.TrimPrefix(path, ":")
+	path = strings.TrimPrefix(path, "*")
 	return strings.TrimSuffix(path, "/")
 }
 
+// endpoint is a handler registered for a full url pattern
+type endpoint struct {
+	name     string
+	method   string
+	pattern  string
+	wildcard bool
+	source   string // file and line the endpoint was registered from
+	host     string // host pattern set with WithHost, empty for the default host
+
+	// names of the named parameters, in the order they appear in the pattern. The
+	// names belong to the endpoint rather than the route, so patterns that share a
+	// named route can each use their own names
+	paramNames []string
+
+	handlerFunc http.HandlerFunc
+	middleware  []Middleware
+	operation   *Operation // description of the route for the OpenAPI document
+	mounted     bool       // set on the routes registered by Mount
+	matchers    []Matcher  // conditions a request must meet, set with WithMatchers
+
+	// info is shared by every request when the pattern has no named parameters
+	info *RouteInfo
+}
+
 type route struct {
-	name string
+	name   string
+	prefix string // the pattern up to and including this route
+
+	// set on named routes that only match values accepted by the constraint
+	constraint     string
+	constraintFunc Constraint
 
-	namedChildren *route
+	// named routes with constraints are always ordered before the route without one
+	namedChildren []*route
 	urlChildren   routes
 
-	handlerFunc  http.HandlerFunc
-	wildcardFunc http.HandlerFunc
+	handler  candidates
+	catchAll candidates // a named parameter that captures the rest of the path
+	wildcard candidates
 }
 
 // Splits strings on the "/" index each string will not start with a '/'
@@ -89,106 +153,496 @@ func splitPaths(path string) ([]string, bool) {
 	return splitPaths, splitPaths[len(splitPaths)-1] == "/"
 }
 
-// used to construct the url paths
-func (r *route) addUrl(path string, handlerFunc http.HandlerFunc) {
-	splitPaths, wildcard := splitPaths(path)
+// used to construct the url paths. The route is never modified, instead every route along
+// the pattern's path is copied, and the copy of the route is returned. This errors if the
+// pattern is malformed, if the pattern reuses a named parameter, or if the same route was
+// already registered with different names. An already registered route is only replaced
+// when overwrite is true. When strict is true, this also errors if the endpoint could never
+// be reached, or would make an already registered endpoint unreachable
+func (r *route) addUrl(newEndpoint *endpoint, constraints Constraints, overwrite, strict bool) (*route, error) {
+	splitPaths, wildcard := splitPaths(newEndpoint.pattern)
+	defer func() {
+		if len(newEndpoint.paramNames) == 0 {
+			newEndpoint.info = newEndpoint.routeInfo(Params{pattern: newEndpoint.pattern})
+		}
+	}()
+
+	root := r.clone()
+	var err error
+
+	currentRoute := root
+	for index, path := range splitPaths {
+		// this is a catch all parameter
+		if strings.HasPrefix(path, "*") {
+			if index != len(splitPaths)-1 {
+				return nil, fmt.Errorf("%w: catch all parameter %q is not at the end of the path", ErrInvalidPattern, path)
+			}
+
+			name, constraint := splitNamedParameter(path)
+			if constraint != "" {
+				return nil, fmt.Errorf("%w: catch all parameter %q has a constraint", ErrInvalidPattern, path)
+			}
+
+			if err = newEndpoint.addParamName(name); err != nil {
+				return nil, err
+			}
+
+			// the catch all always matches before the wildcard of the same route
+			if strict && len(currentRoute.wildcard) != 0 {
+				return nil, fmt.Errorf("%w: the wildcard %q registered at %s could never be reached", ErrAmbiguousRoute, currentRoute.wildcard[0].pattern, currentRoute.wildcard[0].source)
+			}
+
+			// two catch alls with different names would capture the same paths
+			if strict {
+				for _, existing := range currentRoute.catchAll {
+					if !equalNames(existing.paramNames, newEndpoint.paramNames) {
+						return nil, fmt.Errorf("%w: the catch all %q registered at %s captures the same paths", ErrAmbiguousRoute, existing.pattern, existing.source)
+					}
+				}
+			}
+
+			newEndpoint.wildcard = true
+			if currentRoute.catchAll, err = currentRoute.catchAll.add(newEndpoint, overwrite); err != nil {
+				return nil, err
+			}
+
+			return root, nil
+		}
 
-	currentRoute := r
-	for _, path := range splitPaths {
 		// this is a named parameters
 		if strings.HasPrefix(path, ":") {
-			if currentRoute.namedChildren == nil {
-				currentRoute.namedChildren = &route{name: trimPaths(path), handlerFunc: handlerFunc}
-			} else {
-				currentRoute.namedChildren.name = trimPaths(path)
+			name, constraint, constraintFunc, err := parseNamedParameter(path, constraints)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = newEndpoint.addParamName(name); err != nil {
+				return nil, err
 			}
 
 			// update the new route
-			currentRoute = currentRoute.namedChildren
+			currentRoute = currentRoute.namedChild(constraint, func() *route {
+				return &route{name: name, prefix: currentRoute.prefix + path, constraint: constraint, constraintFunc: constraintFunc}
+			})
 			continue
 		}
 
 		// this is url route path
-		if currentRoute.urlChildren == nil {
-			currentRoute.urlChildren = routes{}
-		}
-
 		if childRoute, ok := currentRoute.urlChildren[path]; ok {
-			currentRoute = childRoute
+			currentRoute.urlChildren[path] = childRoute.clone()
 		} else {
-			currentRoute.urlChildren[path] = &route{name: trimPaths(path)}
-			currentRoute = currentRoute.urlChildren[path]
+			if currentRoute.urlChildren == nil {
+				currentRoute.urlChildren = routes{}
+			}
+
+			currentRoute.urlChildren[path] = &route{name: trimPaths(path), prefix: currentRoute.prefix + path}
 		}
+
+		currentRoute = currentRoute.urlChildren[path]
 	}
 
 	// add the handler or wildcard if it is true
 	if wildcard {
-		currentRoute.wildcardFunc = handlerFunc
+		if strict && len(currentRoute.catchAll) != 0 {
+			return nil, fmt.Errorf("%w: the catch all %q registered at %s always matches first", ErrAmbiguousRoute, currentRoute.catchAll[0].pattern, currentRoute.catchAll[0].source)
+		}
+
+		newEndpoint.wildcard = true
+		currentRoute.wildcard, err = currentRoute.wildcard.add(newEndpoint, overwrite)
 	} else {
-		currentRoute.handlerFunc = handlerFunc
+		currentRoute.handler, err = currentRoute.handler.add(newEndpoint, overwrite)
 	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
 }
 
-// used to parse server requests, determining which handler to use
-func (r *route) serveHTTP(path string, w http.ResponseWriter, req *http.Request) bool {
-	splitPaths, _ := splitPaths(path)
+// removeUrl returns a copy of the route without the endpoints registered for the pattern,
+// along with the removed endpoints. Every endpoint registered for the pattern is removed,
+// whatever its matchers. Routes left without any endpoints or children are pruned, so the
+// copy is nil when nothing is left. When the pattern is not registered, the route is
+// returned unchanged and there are no removed endpoints
+func (r *route) removeUrl(pattern string) (*route, candidates) {
+	splitPaths, wildcard := splitPaths(pattern)
+	return r.remove(pattern, splitPaths, wildcard)
+}
+
+func (r *route) remove(pattern string, paths []string, wildcard bool) (*route, candidates) {
+	cloned := r.clone()
+	var removed candidates
+
+	switch {
+	case len(paths) == 0:
+		if wildcard {
+			cloned.wildcard, removed = r.wildcard.without(pattern)
+		} else {
+			cloned.handler, removed = r.handler.without(pattern)
+		}
+	case strings.HasPrefix(paths[0], "*"):
+		if len(paths) == 1 {
+			cloned.catchAll, removed = r.catchAll.without(pattern)
+		}
+	case strings.HasPrefix(paths[0], ":"):
+		_, constraint := splitNamedParameter(paths[0])
+
+		for index, namedChild := range r.namedChildren {
+			if namedChild.constraint == constraint {
+				var child *route
+				if child, removed = namedChild.remove(pattern, paths[1:], wildcard); child == nil {
+					cloned.namedChildren = append(cloned.namedChildren[:index], cloned.namedChildren[index+1:]...)
+				} else {
+					cloned.namedChildren[index] = child
+				}
+				break
+			}
+		}
+	default:
+		if urlChild, ok := r.urlChildren[paths[0]]; ok {
+			var child *route
+			if child, removed = urlChild.remove(pattern, paths[1:], wildcard); child == nil {
+				delete(cloned.urlChildren, paths[0])
+			} else {
+				cloned.urlChildren[paths[0]] = child
+			}
+		}
+	}
+
+	// the same route with other parameter names was never registered
+	if len(removed) == 0 {
+		return r, nil
+	}
 
-	if callabck := r.parseWithNamedParameters(splitPaths, req); callabck != nil {
-		callabck(w, req)
-		return true
+	if len(cloned.handler) == 0 && len(cloned.wildcard) == 0 && len(cloned.catchAll) == 0 && len(cloned.urlChildren) == 0 && len(cloned.namedChildren) == 0 {
+		return nil, removed
 	}
 
-	return false
+	return cloned, removed
 }
 
-func (r *route) parseWithNamedParameters(paths []string, req *http.Request) http.HandlerFunc {
-	// this is a proper url found
-	if len(paths) == 0 {
+// find returns the first endpoint registered for the pattern, or nil if there is none
+func (r *route) find(pattern string) *endpoint {
+	splitPaths, wildcard := splitPaths(pattern)
+
+	var found candidates
+	currentRoute := r
+	for index, path := range splitPaths {
+		switch {
+		case strings.HasPrefix(path, "*"):
+			if index == len(splitPaths)-1 {
+				found = currentRoute.catchAll
+			}
+		case strings.HasPrefix(path, ":"):
+			_, constraint := splitNamedParameter(path)
+
+			var namedRoute *route
+			for _, namedChild := range currentRoute.namedChildren {
+				if namedChild.constraint == constraint {
+					namedRoute = namedChild
+				}
+			}
+			currentRoute = namedRoute
+		default:
+			currentRoute = currentRoute.urlChildren[path]
+		}
+
+		if currentRoute == nil || found != nil {
+			break
+		}
+	}
+
+	switch {
+	case found != nil:
+	case currentRoute == nil:
 		return nil
+	case wildcard:
+		found = currentRoute.wildcard
+	default:
+		found = currentRoute.handler
 	}
 
-	if urlChild, ok := r.urlChildren[paths[0]]; ok {
-		switch len(paths) {
-		case 1:
-			if urlChild.handlerFunc != nil {
-				return urlChild.handlerFunc
-			}
+	return found.find(pattern)
+}
+
+// clone returns a copy of the route that can have its children replaced, without
+// changing the original route. The children themselves are shared
+func (r *route) clone() *route {
+	cloned := *r
+
+	if r.urlChildren != nil {
+		cloned.urlChildren = make(routes, len(r.urlChildren))
+		for path, urlChild := range r.urlChildren {
+			cloned.urlChildren[path] = urlChild
+		}
+	}
+
+	cloned.namedChildren = append([]*route(nil), r.namedChildren...)
+
+	return &cloned
+}
+
+// namedChild returns a copy of the named route with the same constraint, or adds a new one.
+// The route must already be a copy, since the named route is replaced in place
+func (r *route) namedChild(constraint string, newRoute func() *route) *route {
+	for index, namedChild := range r.namedChildren {
+		if namedChild.constraint == constraint {
+			r.namedChildren[index] = namedChild.clone()
+			return r.namedChildren[index]
+		}
+	}
+
+	namedChild := newRoute()
+
+	if constraint == "" {
+		r.namedChildren = append(r.namedChildren, namedChild)
+	} else {
+		// keep the constrained routes in registration order, before the unconstrained route
+		index := len(r.namedChildren)
+		if index > 0 && r.namedChildren[index-1].constraint == "" {
+			index--
+		}
+
+		r.namedChildren = append(r.namedChildren[:index], append([]*route{namedChild}, r.namedChildren[index:]...)...)
+	}
+
+	return namedChild
+}
+
+// accepts reports if the named route can match the value
+func (r *route) accepts(value string) bool {
+	return value != "/" && (r.constraintFunc == nil || r.constraintFunc(value))
+}
+
+func (e *endpoint) addParamName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: a named parameter has no name", ErrInvalidPattern)
+	}
+
+	for _, paramName := range e.paramNames {
+		if paramName == name {
+			return fmt.Errorf("%w: named parameter %q is used more than once", ErrConflictingParams, name)
+		}
+	}
+
+	e.paramNames = append(e.paramNames, name)
+	return nil
+}
+
+// replace returns the endpoint to use in place of an already registered one for the same
+// route. The same pattern overwrites the previous handler when overwrite is true, but
+// registering the route again with different parameter names is always a conflict, since a
+// request could never tell them apart
+func (e *endpoint) replace(existing *endpoint, overwrite bool) (*endpoint, error) {
+	switch {
+	case existing == nil:
+		return e, nil
+	case !equalNames(existing.paramNames, e.paramNames):
+		return nil, fmt.Errorf("%w: the route is already registered as %q at %s", ErrConflictingParams, existing.pattern, existing.source)
+	case !overwrite:
+		return nil, fmt.Errorf("%w as %q at %s", ErrDuplicateRoute, existing.pattern, existing.source)
+	default:
+		return e, nil
+	}
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for index := range a {
+		if a[index] != b[index] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// valuesPool holds the buffers used to collect named parameter values while a request
+// is being resolved. The values are only copied out of the buffer once a route matches
+var valuesPool = sync.Pool{
+	New: func() any {
+		values := make([]string, 0, 8)
+		return &values
+	},
+}
+
+// used to parse server requests, determining which endpoint to use. The returned request
+// includes the named parameters of the endpoint, merged with the named parameters of the host.
+// The request is always copied to attach the route, since GetRouteInfo reads it from the
+// request's context, but routes without named parameters share their RouteInfo.
+// When the path matches, but no endpoint's matchers pass, the endpoint is nil and the status
+// of the first matcher that failed is returned
+func (r *route) lookup(path string, req *http.Request, host Params) (*endpoint, *http.Request, int) {
+	buffer := valuesPool.Get().(*[]string)
+	defer func() {
+		*buffer = (*buffer)[:0]
+		valuesPool.Put(buffer)
+	}()
+
+	found, values := r.parseWithNamedParameters(path, *buffer)
+	foundEndpoint, status := found.choose(req)
+	if foundEndpoint == nil {
+		return nil, req, status
+	}
+
+	// update the context to include the matched route. Routes without any named
+	// parameters share the same info, unless a parent router already set parameters
+	info := foundEndpoint.info
+	if parent := GetParams(req.Context()).with(host); info == nil || parent.Len() != 0 {
+		info = foundEndpoint.routeInfo(newParams(foundEndpoint, values, parent))
+	}
+
+	return foundEndpoint, req.WithContext(&matchContext{Context: req.Context(), info: info}), 0
+}
+
+func (e *endpoint) routeInfo(params Params) *RouteInfo {
+	return &RouteInfo{Method: e.method, Host: e.host, Pattern: e.pattern, Params: params, Wildcard: e.wildcard}
+}
+
+// newParams copies the values for an endpoint out of the pooled buffer. Any parameters
+// already set by a parent router are kept, unless the endpoint uses the same name. The
+// parent's parameters are never modified
+func newParams(foundEndpoint *endpoint, values []string, parent Params) Params {
+	params := Params{pattern: foundEndpoint.pattern, names: foundEndpoint.paramNames}
+
+	if parent.Len() == 0 {
+		params.values = make([]string, len(values))
+		copy(params.values, values)
+		return params
+	}
 
-			return urlChild.wildcardFunc
+	params.names = make([]string, 0, parent.Len()+len(foundEndpoint.paramNames))
+	params.values = make([]string, 0, parent.Len()+len(values))
+	for index, name := range parent.names {
+		if !contains(foundEndpoint.paramNames, name) {
+			params.names = append(params.names, name)
+			params.values = append(params.values, parent.values[index])
+		}
+	}
+
+	params.names = append(params.names, foundEndpoint.paramNames...)
+	params.values = append(params.values, values...)
+	return params
+}
+
+// nextPath splits the first path off of a url. A '/' is always a path on its own
+func nextPath(url string) (string, string) {
+	if url[0] == '/' {
+		return url[:1], url[1:]
+	}
+
+	if index := strings.IndexByte(url, '/'); index >= 0 {
+		return url[:index], url[index:]
+	}
+
+	return url, ""
+}
+
+// used to find the deepest route that matches the start of a url and how much of the url it consumed
+func (r *route) deepestMatch(url string) (*route, int) {
+	deepest, depth := r, 0
+
+	if url == "" {
+		return deepest, depth
+	}
+
+	path, remaining := nextPath(url)
+
+	if urlChild, ok := r.urlChildren[path]; ok {
+		if found, matched := urlChild.deepestMatch(remaining); matched+len(path) > depth {
+			deepest, depth = found, matched+len(path)
+		}
+	}
+
+	for _, namedChild := range r.namedChildren {
+		if !namedChild.accepts(path) {
+			continue
+		}
+
+		if found, matched := namedChild.deepestMatch(remaining); matched+len(path) > depth {
+			deepest, depth = found, matched+len(path)
+		}
+	}
+
+	return deepest, depth
+}
+
+// used to check if a path has a handler
+func (r *route) matches(path string) bool {
+	found, _ := r.parseWithNamedParameters(path, nil)
+	return found != nil
+}
+
+// parseWithNamedParameters returns the candidate endpoints for the remaining url along with
+// the values of every named parameter that was passed through, in the order they were found.
+// The candidates are chosen between by their matchers once the url is resolved. The url is
+// walked in place and the values are collected into the given buffer, so the walk itself
+// never allocates. Serving a request still copies the request once to attach the route.
+//
+// When a branch fails deeper down, the next branch at the same level is tried. The
+// branches are always tried in the same priority:
+//  1. url paths that match exactly
+//  2. named parameters, which match any path other than a '/'. Named parameters with a
+//     constraint are tried first, in the order they were registered. When the path ends at a
+//     named parameter, the wildcard or catch all directly beneath it is used if it has no handler
+//  3. the catch all parameter of the current route, which captures everything that remains
+//  4. the wildcard of the current route, which matches everything that remains
+func (r *route) parseWithNamedParameters(url string, values []string) (candidates, []string) {
+	// this is a proper url found
+	if url == "" {
+		switch {
+		case len(r.handler) != 0:
+			return r.handler, values
+		case len(r.catchAll) != 0:
+			return r.catchAll, append(values, "")
 		default:
-			callback := urlChild.parseWithNamedParameters(paths[1:], req)
+			return r.wildcard, values
+		}
+	}
 
-			if callback != nil {
-				return callback
-			}
+	path, remaining := nextPath(url)
 
-			// try to return the wild card on the chid if there is one
-			return urlChild.wildcardFunc
+	if urlChild, ok := r.urlChildren[path]; ok {
+		if found, foundValues := urlChild.parseWithNamedParameters(remaining, values); found != nil {
+			return found, foundValues
 		}
 	}
 
 	// this is a named parameter
-	if r.namedChildren != nil {
-		switch len(paths) {
-		case 1:
-			// have an exact match for a named child.
-			if r.namedChildren.handlerFunc != nil {
-				*req = *setNamedParameter(r.namedChildren.name, paths[0], req)
-				return r.namedChildren.handlerFunc
-			}
+	for _, namedChild := range r.namedChildren {
+		if !namedChild.accepts(path) {
+			continue
+		}
 
-			// named children will never have wildcards
-		default:
-			callback := r.namedChildren.parseWithNamedParameters(paths[1:], req)
+		namedValues := append(values, path)
+
+		if found, foundValues := namedChild.parseWithNamedParameters(remaining, namedValues); found != nil {
+			return found, foundValues
+		}
 
-			// update the context to include the named parameter
-			if callback != nil {
-				*req = *setNamedParameter(r.namedChildren.name, paths[0], req)
-				return callback
+		// a wildcard directly beneath a named parameter, such as "/:tenant/", also matches
+		// when the path ends at the named parameter
+		if urlChild, ok := namedChild.urlChildren["/"]; ok && remaining == "" {
+			if found, foundValues := urlChild.parseWithNamedParameters("", namedValues); found != nil {
+				return found, foundValues
 			}
 		}
 	}
 
+	// capture everything that remains
+	if len(r.catchAll) != 0 {
+		return r.catchAll, append(values, url)
+	}
+
+	// try to return the wild card if there is one
+	if len(r.wildcard) != 0 {
+		return r.wildcard, values
+	}
+
 	// at this point, there is nothing to return, hit a bad index
-	return nil
+	return nil, nil
 }
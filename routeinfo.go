@@ -0,0 +1,31 @@
+package urlrouter
+
+import "context"
+
+// RouteInfo describes the registered route that matched a request
+type RouteInfo struct {
+	// Method the route was registered under. This is GET when a HEAD request is served by a GET route
+	Method string
+
+	// Host pattern the route was registered for with WithHost. This is empty for the default host
+	Host string
+
+	// Pattern the route was registered with, such as "/users/:id"
+	Pattern string
+
+	// Params are the named parameters of the route
+	Params Params
+
+	// Wildcard is true when the route ends in a '/' wildcard or a catch all parameter
+	Wildcard bool
+}
+
+// GetRouteInfo returns the route that matched the request. This reports false when
+// no route was matched, such as in the NotFound and MethodNotAllowed handlers
+func GetRouteInfo(ctx context.Context) (RouteInfo, bool) {
+	if value, ok := ctx.Value(NAMED_PAAMTERS).(*RouteInfo); ok {
+		return *value, true
+	}
+
+	return RouteInfo{}, false
+}
@@ -0,0 +1,72 @@
+package urlrouter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRouteInfo(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	var routeInfo RouteInfo
+	var found bool
+	recordHandler := func(w http.ResponseWriter, r *http.Request) {
+		routeInfo, found = GetRouteInfo(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}
+
+	router := New()
+	router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routeInfo, found = GetRouteInfo(r.Context())
+		w.WriteHeader(http.StatusNotFound)
+	})
+	router.HandleFunc("GET", "/static", recordHandler)
+	router.HandleFunc("GET", "/users/:id", recordHandler)
+	router.HandleFunc("POST", "/v1/", recordHandler)
+	router.HandleFunc("GET", "/files/*filepath", recordHandler)
+	router.Mount("/mounted", http.HandlerFunc(recordHandler))
+
+	testServer := httptest.NewServer(router)
+	defer testServer.Close()
+
+	tests := []struct {
+		method          string
+		path            string
+		found           bool
+		expectedMethod  string
+		pattern         string
+		wildcard        bool
+		namedParameters map[string]string
+	}{
+		{method: "GET", path: "/static", found: true, expectedMethod: "GET", pattern: "/static"},
+		{method: "HEAD", path: "/static", found: true, expectedMethod: "GET", pattern: "/static"},
+		{method: "GET", path: "/users/42", found: true, expectedMethod: "GET", pattern: "/users/:id", namedParameters: map[string]string{"id": "42"}},
+		{method: "POST", path: "/v1/anything", found: true, expectedMethod: "POST", pattern: "/v1/", wildcard: true},
+		{method: "GET", path: "/files/a/b", found: true, expectedMethod: "GET", pattern: "/files/*filepath", wildcard: true, namedParameters: map[string]string{"filepath": "a/b"}},
+		{method: "PUT", path: "/mounted/a/b", found: true, expectedMethod: "PUT", pattern: "/mounted/", wildcard: true},
+		{method: "GET", path: "/unknown", found: false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("It records the route for %s %s", test.method, test.path), func(t *testing.T) {
+			routeInfo, found = RouteInfo{}, false
+
+			request, err := http.NewRequest(test.method, fmt.Sprintf("%s%s", testServer.URL, test.path), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(found).To(Equal(test.found))
+			g.Expect(routeInfo.Method).To(Equal(test.expectedMethod))
+			g.Expect(routeInfo.Pattern).To(Equal(test.pattern))
+			g.Expect(routeInfo.Wildcard).To(Equal(test.wildcard))
+			g.Expect(routeInfo.Params.Map()).To(Equal(test.namedParameters))
+		})
+	}
+}
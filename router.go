@@ -2,29 +2,176 @@
 package urlrouter
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
 )
 
 type routes map[string]*route
 
+var (
+	// ErrInvalidMethod is returned when registering a route with a method that is not a valid HTTP token
+	ErrInvalidMethod = errors.New("invalid method")
+
+	// ErrInvalidPattern is returned when registering a route with a malformed pattern
+	ErrInvalidPattern = errors.New("invalid pattern")
+
+	// ErrInvalidHandler is returned when registering a route without a handler
+	ErrInvalidHandler = errors.New("handler is nil")
+
+	// ErrDuplicateRoute is returned when registering a route that is already registered
+	ErrDuplicateRoute = errors.New("route is already registered")
+
+	// ErrConflictingParams is returned when registering a route that uses the same named parameter
+	// more than once, or that is already registered with different named parameters
+	ErrConflictingParams = errors.New("conflicting named parameters")
+
+	// ErrDuplicateName is returned when registering a route with a name used by another pattern
+	ErrDuplicateName = errors.New("route name is already used")
+
+	// ErrAmbiguousRoute is returned by a strict router when registering a route that could
+	// never be reached, or that would stop an already registered route from being reached
+	ErrAmbiguousRoute = errors.New("ambiguous route")
+)
+
+// RouteError describes a route that could not be registered. When the route conflicts with
+// an already registered route, the error includes the other route and where it was registered
+type RouteError struct {
+	Method  string // method the route was registered for
+	Pattern string // pattern of the route
+	Source  string // file and line the route was registered from
+	Err     error
+}
+
+func (e *RouteError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("route %s %q registered at %s: %s", e.Method, e.Pattern, e.Source, e.Err)
+	}
+
+	return fmt.Sprintf("route %s %q: %s", e.Method, e.Pattern, e.Err)
+}
+
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
+
+// Router is safe to use from multiple goroutines. Routes and middleware can be added while
+// requests are being served, and each request sees either all or none of a registration.
+// The exported fields must be set before the router starts serving requests
 type Router struct {
-	routes routes
+	// DisableAutoHEAD stops HEAD requests from being served by the GET handler of
+	// the same path when no HEAD handler was registered.
+	DisableAutoHEAD bool
+
+	// DisableAutoOPTIONS stops OPTIONS requests from being answered automatically
+	// with an Allow header when no OPTIONS handler was registered.
+	DisableAutoOPTIONS bool
+
+	// NotFound is called when no route matches the request's path. The closest partially
+	// matched route prefix can be read with GetPartialMatch. Defaults to http.NotFoundHandler()
+	NotFound http.Handler
+
+	// MethodNotAllowed is called when the request's path is registered under other methods.
+	// The Allow header is already set on the response and the closest partially matched route
+	// prefix can be read with GetPartialMatch. Defaults to a plain text 405 response
+	MethodNotAllowed http.Handler
+
+	// MatcherFailed is called when a route matches the request's path, but none of the
+	// route's matchers pass. The status of the first matcher that failed can be read with
+	// GetMatcherStatus. Defaults to a plain text response with that status, such as 406 or 415
+	MatcherFailed http.Handler
+
+	constraints Constraints
+	strict      bool
+	validation  bool
+	maxBodySize int64
+
+	lock  sync.Mutex
+	table atomic.Pointer[table]
+}
+
+// Option configures a Router when it is constructed
+type Option func(router *Router)
+
+// WithConstraints registers a library of constraints that named parameters can reference
+// by name, such as "/users/:id<int>". The constraints "int", "uuid", "alpha" and "alnum"
+// are always available, but can be replaced by a constraint with the same name
+func WithConstraints(constraints Constraints) Option {
+	return func(router *Router) {
+		for name, constraint := range constraints {
+			router.constraints[name] = constraint
+		}
+	}
 }
 
-func New() *Router {
-	return &Router{
-		routes: routes{},
+// WithStrict rejects routes that are already registered, instead of replacing them. It also
+// rejects catch all parameters that are ambiguous: a wildcard and a catch all parameter
+// registered for the same path, since the catch all parameter would always match first, and
+// two catch all parameters with different names for the same path. HandleFunc panics and Add
+// returns an ErrDuplicateRoute or ErrAmbiguousRoute error, naming both routes and where they
+// were registered.
+//
+// Named parameters with overlapping constraints are not rejected, since constrained named
+// parameters are tried in the order they were registered and fall through to the next one
+func WithStrict() Option {
+	return func(router *Router) {
+		router.strict = true
 	}
 }
 
+func New(options ...Option) *Router {
+	router := &Router{
+		constraints: Constraints{},
+	}
+	router.table.Store(&table{routes: routes{}, names: map[string]*endpoint{}})
+
+	for _, option := range options {
+		option(router)
+	}
+
+	return router
+}
+
 // Add a new url handler to the router. If a route already exists with the same url
-// path, then this will overwrite the previous handler.
+// path, then this will overwrite the previous handler, unless the router was created
+// WithStrict.
+//
+// Named parameters are declared with a ':' prefix, such as "/users/:id". Each route keeps its
+// own names, so "/users/:id/posts" and "/users/:userID/settings" can both be registered. This
+// will panic if the same path is registered again with different names, or if a path uses
+// the same name more than once.
+//
+// Named parameters can be constrained, so a value that does not pass the constraint falls
+// through to the other routes. A constraint is either a regular expression that must match
+// the whole value, such as "/users/:id{[0-9]+}", or a constraint registered with
+// WithConstraints, such as "/users/:id<int>". Constrained parameters are tried before the
+// unconstrained parameter at the same path level.
+//
+// A catch all parameter is declared with a '*' prefix as the last path, such as "/files/*filepath".
+// It captures the rest of the request's path, including any '/', as a named parameter.
+//
+// A path ending in '/' is a wildcard that matches anything not captured by a more explicit
+// path. A wildcard directly after a named parameter, such as "/:tenant/", also matches the
+// named parameter on its own, so "/acme" and "/acme/anything/deep" are both captured. An
+// exact "/:tenant" route is still preferred for "/acme".
+//
+// Requests are resolved by trying url paths first, then named parameters, catch all
+// parameters and finally wildcards, backtracking whenever a branch fails deeper down.
 //
 //		PARAMS:
 //		- method - API method to match against. Commonly one of: POST, PUT, PATCH, GET, DELETE
 //		- path - The path of a URL. This will panic if path is the empty string
 //	 - handlerFunc - handler callback to used when a pathi is found. This will panic if the handlerFunc is nil
-func (router *Router) HandleFunc(method string, path string, handlerFunc http.HandlerFunc) {
+//	 - options - optional configuration for the route, such as WithMiddleware
+func (router *Router) HandleFunc(method string, path string, handlerFunc http.HandlerFunc, options ...RouteOption) {
 	if path == "" {
 		panic("recieved an empty path")
 	}
@@ -33,26 +180,318 @@ func (router *Router) HandleFunc(method string, path string, handlerFunc http.Ha
 		panic("received and empty handler function")
 	}
 
-	var foundRoute *route
+	if err := router.add(method, path, handlerFunc, !router.strict, options); err != nil {
+		panic(err)
+	}
+}
+
+// Add a new url handler to the router, the same as HandleFunc. Instead of panicking, this
+// returns a RouteError when the route can't be registered, and never replaces a route that
+// is already registered. Paths that could never match a request are also rejected, such as
+// paths that do not start with '/', or that contain an empty path or whitespace. The
+// RouteError wraps one of ErrInvalidMethod, ErrInvalidPattern, ErrInvalidHandler,
+// ErrDuplicateRoute, ErrConflictingParams or ErrDuplicateName, or ErrAmbiguousRoute when
+// the router was created WithStrict
+func (router *Router) Add(method string, path string, handler http.Handler, options ...RouteOption) error {
+	if !validMethod(method) {
+		return &RouteError{Method: method, Pattern: path, Source: registeredAt(), Err: ErrInvalidMethod}
+	}
+
+	if err := validPattern(path); err != nil {
+		return &RouteError{Method: method, Pattern: path, Source: registeredAt(), Err: err}
+	}
+
+	if nilHandler(handler) {
+		return &RouteError{Method: method, Pattern: path, Source: registeredAt(), Err: ErrInvalidHandler}
+	}
+
+	return router.add(method, path, handler, false, options)
+}
+
+// add registers the handler, only replacing a route that is already registered when
+// overwrite is true
+func (router *Router) add(method string, path string, handler http.Handler, overwrite bool, options []RouteOption) error {
+	newEndpoint := router.newEndpoint(method, path, handler, options)
+
+	return router.update(func(updated *table) error {
+		return updated.add(newEndpoint, router.constraints, overwrite, router.strict)
+	})
+}
+
+// newEndpoint creates the endpoint for a route, recording where it was registered from
+func (router *Router) newEndpoint(method string, path string, handler http.Handler, options []RouteOption) *endpoint {
+	newEndpoint := &endpoint{method: method, pattern: path, source: registeredAt()}
+	for _, option := range options {
+		option(newEndpoint)
+	}
+
+	// requests are validated after the route's middleware, right before the handler
+	if router.validation && newEndpoint.operation != nil {
+		handler = validate(newEndpoint.operation, router.maxBodySize, handler)
+	}
+
+	// route middleware runs after matching, so it is applied once when registering
+	newEndpoint.handlerFunc = chain(newEndpoint.middleware, handler).ServeHTTP
+
+	return newEndpoint
+}
+
+// packageDir is the directory of the router's own source files
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// registeredAt returns the file and line that registered a route, skipping the router's own
+// source files, so routes registered through a Group or Mount report the caller's location
+func registeredAt() string {
+	callers := make([]uintptr, 32)
+	frames := runtime.CallersFrames(callers[:runtime.Callers(2, callers)])
+
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// validPattern errors when a path could never match a request's path. The path must start
+// with '/', and only the last path can be empty, for a wildcard. Paths can't contain
+// whitespace or control characters, except in the constraint of a named parameter
+func validPattern(path string) error {
+	if path == "" {
+		return fmt.Errorf("%w: the path is empty", ErrInvalidPattern)
+	}
+
+	if path[0] != '/' {
+		return fmt.Errorf("%w: the path does not start with '/'", ErrInvalidPattern)
+	}
+
+	segments := strings.Split(path[1:], "/")
+	for index, segment := range segments {
+		if segment == "" && index != len(segments)-1 {
+			return fmt.Errorf("%w: the path contains an empty path between '/'", ErrInvalidPattern)
+		}
+
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segment, _ = splitNamedParameter(segment)
+		}
+
+		if strings.IndexFunc(segment, func(char rune) bool { return unicode.IsSpace(char) || unicode.IsControl(char) }) >= 0 {
+			return fmt.Errorf("%w: the path %q contains whitespace", ErrInvalidPattern, segment)
+		}
+	}
+
+	return nil
+}
+
+// nilHandler reports if the handler is nil, including a nil http.HandlerFunc
+func nilHandler(handler http.Handler) bool {
+	if handlerFunc, ok := handler.(http.HandlerFunc); ok {
+		return handlerFunc == nil
+	}
+
+	return handler == nil
+}
+
+// validMethod reports if the method is a valid HTTP token
+func validMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+
+	for _, char := range method {
+		if char > unicode.MaxASCII || !(unicode.IsLetter(char) || unicode.IsDigit(char) || strings.ContainsRune("!#$%&'*+-.^_`|~", char)) {
+			return false
+		}
+	}
+
+	return true
+}
 
-	if knownRoute, ok := router.routes[method]; ok {
-		foundRoute = knownRoute
-	} else {
-		foundRoute = &route{name: method}
-		router.routes[method] = foundRoute
+// Remove the url handler registered for the method and path, reporting if there was one.
+// The path must be the same pattern that was used to register the handler, including the
+// names and constraints of any named parameters. Handlers registered for the pattern with
+// different matchers are all removed. Requests that already matched the handler are not
+// affected.
+//
+// Routes registered for a host are removed by passing the same WithHost option, and a host
+// is removed once it has no routes left. Any other options are ignored.
+func (router *Router) Remove(method string, path string, options ...RouteOption) bool {
+	target := &endpoint{method: method, pattern: path}
+	for _, option := range options {
+		option(target)
 	}
 
-	foundRoute.addUrl(path, handlerFunc)
+	removed := false
+
+	_ = router.update(func(updated *table) error {
+		removed = len(updated.remove(target.host, method, path)) != 0
+		return nil
+	})
+
+	return removed
 }
 
 func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// every request is served by a single table, even if routes are added while it is running
+	current := router.table.Load()
+	routes, host := current.hostRoutes(r)
+	parent := r.Context()
+	handler, r := router.handler(routes, host, r)
+
+	if len(current.middleware) == 0 {
+		handler.ServeHTTP(w, r)
+		return
+	}
+
+	// router middleware runs after matching, so it can read the matched route. The middleware
+	// was already built by Use, and reaches the matched handler through the request's context
+	matched, ok := r.Context().(*matchContext)
+	if !ok || matched == parent {
+		matched = &matchContext{Context: r.Context()}
+		r = r.WithContext(matched)
+	}
+
+	matched.handler, matched.middleware = handler, current.middleware
+	current.middleware[0].ServeHTTP(w, r)
+}
+
+// handler returns the handler for a request, along with the request updated to include the
+// matched route. When no route matches, this is the NotFound or MethodNotAllowed handler.
+// When a route matches, but none of its matchers pass, this answers with the status of the
+// first matcher that failed
+func (router *Router) handler(routes routes, host Params, r *http.Request) (http.Handler, *http.Request) {
 	method := r.Method
 
-	if route, ok := router.routes[method]; ok {
-		if !route.serveHTTP(r.URL.Path, w, r) {
-			http.NotFoundHandler().ServeHTTP(w, r)
+	if route, ok := routes[method]; ok {
+		foundEndpoint, req, status := route.lookup(r.URL.Path, r, host)
+		if foundEndpoint != nil {
+			return foundEndpoint.handlerFunc, req
+		} else if status != 0 {
+			return router.unmatched(status, r)
+		}
+	}
+
+	switch method {
+	case http.MethodHead:
+		// fall back to the GET handler, but never write the body
+		if route, ok := routes[http.MethodGet]; ok && !router.DisableAutoHEAD {
+			foundEndpoint, req, status := route.lookup(r.URL.Path, r, host)
+			if foundEndpoint != nil {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					foundEndpoint.handlerFunc(headResponseWriter{w}, r)
+				}), req
+			} else if status != 0 {
+				return router.unmatched(status, r)
+			}
+		}
+	case http.MethodOptions:
+		if !router.DisableAutoOPTIONS {
+			if allowed := router.allowedMethods(routes, r); len(allowed) != 0 {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Allow", strings.Join(allowed, ", "))
+					w.WriteHeader(http.StatusNoContent)
+				}), r
+			}
+		}
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), PARTIAL_MATCH, router.partialMatch(routes, r)))
+
+	// the path might still be registered under a different method
+	if allowed := router.allowedMethods(routes, r); len(allowed) != 0 {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+			if router.MethodNotAllowed != nil {
+				router.MethodNotAllowed.ServeHTTP(w, r)
+			} else {
+				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			}
+		}), r
+	}
+
+	if router.NotFound != nil {
+		return router.NotFound, r
+	}
+
+	return http.NotFoundHandler(), r
+}
+
+// partialMatch returns the pattern prefix of the deepest route that matches the start of the
+// request's path. The request's method is checked first, followed by all other methods in order
+func (router *Router) partialMatch(routes routes, r *http.Request) string {
+	methods := make([]string, 0, len(routes))
+	for method := range routes {
+		if method != r.Method {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+
+	if _, ok := routes[r.Method]; ok {
+		methods = append([]string{r.Method}, methods...)
+	}
+
+	prefix, depth := "", 0
+	for _, method := range methods {
+		if deepest, matched := routes[method].deepestMatch(r.URL.Path); matched > depth {
+			prefix, depth = deepest.prefix, matched
+		}
+	}
+
+	return prefix
+}
+
+// allowedMethods returns the sorted list of methods that have a handler for the request's path.
+// A request for the path "*" returns every method known to the router. The automatic HEAD and
+// OPTIONS methods are included when they are enabled.
+func (router *Router) allowedMethods(routes routes, r *http.Request) []string {
+	var allowed []string
+
+	for method, route := range routes {
+		if r.URL.Path == "*" || route.matches(r.URL.Path) {
+			allowed = append(allowed, method)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	if !router.DisableAutoHEAD && contains(allowed, http.MethodGet) && !contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+
+	if !router.DisableAutoOPTIONS && !contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+
+	sort.Strings(allowed)
+	return allowed
+}
+
+func contains(values []string, value string) bool {
+	for _, known := range values {
+		if known == value {
+			return true
 		}
-	} else {
-		http.NotFoundHandler().ServeHTTP(w, r)
 	}
+
+	return false
+}
+
+// headResponseWriter discards the body written by a GET handler that is serving a HEAD request
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
 }
@@ -0,0 +1,111 @@
+package urlrouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// discardResponseWriter is a response writer that does not allocate, so the benchmarks
+// only report the allocations made by the router
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+func benchmarkRouter() *Router {
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	router := New()
+	router.HandleFunc("GET", "/", noop)
+	router.HandleFunc("GET", "/v1/applications", noop)
+	router.HandleFunc("GET", "/v1/applications/local/unix", noop)
+	router.HandleFunc("GET", "/v1/users/:id", noop)
+	router.HandleFunc("GET", "/v1/users/:id/posts/:post", noop)
+	router.HandleFunc("GET", "/v1/files/*filepath", noop)
+
+	return router
+}
+
+func benchmarkServeHTTP(b *testing.B, path string) {
+	router := benchmarkRouter()
+	request := httptest.NewRequest("GET", path, nil)
+	writer := &discardResponseWriter{header: http.Header{}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		router.ServeHTTP(writer, request)
+	}
+}
+
+func BenchmarkRouter_StaticRoute(b *testing.B) {
+	benchmarkServeHTTP(b, "/v1/applications/local/unix")
+}
+
+func BenchmarkRouter_WildcardRoute(b *testing.B) {
+	benchmarkServeHTTP(b, "/v2/unknown/path")
+}
+
+func BenchmarkRouter_NamedParameter(b *testing.B) {
+	benchmarkServeHTTP(b, "/v1/users/42")
+}
+
+func BenchmarkRouter_MultipleNamedParameters(b *testing.B) {
+	benchmarkServeHTTP(b, "/v1/users/42/posts/hello")
+}
+
+func BenchmarkRouter_CatchAllParameter(b *testing.B) {
+	benchmarkServeHTTP(b, "/v1/files/static/css/site.css")
+}
+
+func TestRouter_Allocations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("It resolves routes through the tree without allocating", func(t *testing.T) {
+		route := benchmarkRouter().table.Load().routes["GET"]
+		values := make([]string, 0, 8)
+
+		for _, path := range []string{"/v1/applications/local/unix", "/v1/users/42/posts/hello", "/v1/files/static/css/site.css", "/v2/unknown/path"} {
+			allocs := testing.AllocsPerRun(100, func() {
+				found, _ := route.parseWithNamedParameters(path, values[:0])
+				if found == nil {
+					t.Fatalf("%s did not match", path)
+				}
+			})
+			g.Expect(allocs).To(BeZero(), path)
+		}
+	})
+
+	t.Run("It only copies the request to attach the matched route when serving", func(t *testing.T) {
+		for path, expected := range map[string]float64{
+			// the request and its context, which carries the RouteInfo shared by the route
+			"/v1/applications/local/unix": 2,
+			"/v2/unknown/path":            2,
+			// the RouteInfo and the values of the named parameters are also copied out of the pool
+			"/v1/users/42":                  4,
+			"/v1/users/42/posts/hello":      4,
+			"/v1/files/static/css/site.css": 4,
+		} {
+			router := benchmarkRouter()
+			request := httptest.NewRequest("GET", path, nil)
+			writer := &discardResponseWriter{header: http.Header{}}
+
+			allocs := testing.AllocsPerRun(100, func() { router.ServeHTTP(writer, request) })
+			g.Expect(allocs).To(Equal(expected), path)
+
+			// the router's middleware is built once, and reaches the handler through the same context
+			router.Use(func(next http.Handler) http.Handler { return next })
+			router.Use(func(next http.Handler) http.Handler { return next })
+
+			allocs = testing.AllocsPerRun(100, func() { router.ServeHTTP(writer, request) })
+			g.Expect(allocs).To(Equal(expected), path)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -287,19 +288,7 @@ func TestRouter_UrlPathPatterns(t *testing.T) {
 			defer testServer.Close()
 
 			// v1/ catches anything after the matcher rahter than '/'
-			//request, err := http.NewRequest("POST", fmt.Sprintf("%s/v1/full_match/something", testServer.URL), nil)
-			//g.Expect(err).ToNot(HaveOccurred())
-			//
-			//resp, err := client.Do(request)
-			//g.Expect(err).ToNot(HaveOccurred())
-			//g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
-			//
-			//body, err := io.ReadAll(resp.Body)
-			//g.Expect(err).ToNot(HaveOccurred())
-			//g.Expect(string(body)).To(Equal("catch v1"))
-
-			// still catches the /v1 path
-			request, err := http.NewRequest("POST", fmt.Sprintf("%s/v1", testServer.URL), nil)
+			request, err := http.NewRequest("POST", fmt.Sprintf("%s/v1/full_match/something", testServer.URL), nil)
 			g.Expect(err).ToNot(HaveOccurred())
 
 			resp, err := client.Do(request)
@@ -308,6 +297,18 @@ func TestRouter_UrlPathPatterns(t *testing.T) {
 
 			body, err := io.ReadAll(resp.Body)
 			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(body)).To(Equal("catch v1"))
+
+			// still catches the /v1 path
+			request, err = http.NewRequest("POST", fmt.Sprintf("%s/v1", testServer.URL), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err = client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			body, err = io.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(string(body)).To(Equal("catch all"))
 
 			// full_match catches the exact url properly
@@ -381,17 +382,45 @@ func TestRouter_NamedParameters(t *testing.T) {
 	})
 
 	t.Run("Context behaviors of paths", func(t *testing.T) {
-		t.Run("It overwrites the key words at the same path level", func(t *testing.T) {
-			var namedParameters = map[string]string{}
+		t.Run("It panics when the same path level is registered with different names", func(t *testing.T) {
 			foundHandler := func(w http.ResponseWriter, r *http.Request) {
-				namedParameters = GetNamedParamters(r.Context())
-
 				w.WriteHeader(http.StatusOK)
 			}
 
 			router := New()
 			router.HandleFunc("POST", "/:name", foundHandler)
-			router.HandleFunc("POST", "/:value2", foundHandler)
+			g.Expect(func() { router.HandleFunc("POST", "/:value2", foundHandler) }).To(Panic())
+		})
+
+		t.Run("It panics when multiple path levels are registered with different names", func(t *testing.T) {
+			foundHandler := func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}
+
+			router := New()
+			router.HandleFunc("POST", "/:1/:2/:3", foundHandler)
+			g.Expect(func() { router.HandleFunc("POST", "/:new1/:new2/:new3", foundHandler) }).To(Panic())
+		})
+
+		t.Run("It panics when a pattern uses the same name more than once", func(t *testing.T) {
+			foundHandler := func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}
+
+			router := New()
+			g.Expect(func() { router.HandleFunc("POST", "/:name/:name", foundHandler) }).To(Panic())
+		})
+
+		t.Run("It overwrites the handler when the same pattern is registered again", func(t *testing.T) {
+			router := New()
+			router.HandleFunc("POST", "/:name", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("first"))
+			})
+			router.HandleFunc("POST", "/:name", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("second"))
+			})
 
 			testServer := httptest.NewServer(router)
 			defer testServer.Close()
@@ -402,31 +431,68 @@ func TestRouter_NamedParameters(t *testing.T) {
 			resp, err := client.Do(request)
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
-			g.Expect(namedParameters).To(Equal(map[string]string{"value2": "the_name"}))
+
+			body, err := io.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(body)).To(Equal("second"))
 		})
 
-		t.Run("It overwrittes the key workds at multiple path levels", func(*testing.T) {
+		t.Run("It keeps the names of each route when different names share a path level", func(t *testing.T) {
 			var namedParameters = map[string]string{}
-			foundHandler := func(w http.ResponseWriter, r *http.Request) {
-				namedParameters = GetNamedParamters(r.Context())
-
-				w.WriteHeader(http.StatusOK)
+			foundHandler := func(path string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					namedParameters = GetNamedParamters(r.Context())
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(path))
+				}
 			}
 
 			router := New()
-			router.HandleFunc("POST", "/:1/:2/:3", foundHandler)
-			router.HandleFunc("POST", "/:new1/:new2/:new3", foundHandler)
+			router.HandleFunc("GET", "/users/:id/posts", foundHandler("posts"))
+			router.HandleFunc("GET", "/users/:userID/settings", foundHandler("settings"))
+			router.HandleFunc("GET", "/users/:userID/settings/:setting", foundHandler("setting"))
 
 			testServer := httptest.NewServer(router)
 			defer testServer.Close()
 
-			request, err := http.NewRequest("POST", fmt.Sprintf("%s/one/two/three", testServer.URL), nil)
+			// match the posts
+			request, err := http.NewRequest("GET", fmt.Sprintf("%s/users/42/posts", testServer.URL), nil)
 			g.Expect(err).ToNot(HaveOccurred())
 
 			resp, err := client.Do(request)
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
-			g.Expect(namedParameters).To(Equal(map[string]string{"new1": "one", "new2": "two", "new3": "three"}))
+			g.Expect(namedParameters).To(Equal(map[string]string{"id": "42"}))
+
+			body, err := io.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(body)).To(Equal("posts"))
+
+			// match the settings
+			request, err = http.NewRequest("GET", fmt.Sprintf("%s/users/42/settings", testServer.URL), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err = client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			g.Expect(namedParameters).To(Equal(map[string]string{"userID": "42"}))
+
+			body, err = io.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(body)).To(Equal("settings"))
+
+			// match a single setting
+			request, err = http.NewRequest("GET", fmt.Sprintf("%s/users/42/settings/theme", testServer.URL), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err = client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			g.Expect(namedParameters).To(Equal(map[string]string{"userID": "42", "setting": "theme"}))
+
+			body, err = io.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(body)).To(Equal("setting"))
 		})
 
 		t.Run("It allows paths ending in a '/' to wildcard match a path not captured with explicit paths", func(t *testing.T) {
@@ -584,3 +650,960 @@ func TestRouter_Mixed_URL_and_Nmed_Paths(t *testing.T) {
 		g.Expect(namedParameters).To(Equal(map[string]string{"name": "the_name"}))
 	})
 }
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	foundHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	t.Run("It returns a 405 with the Allow header when the path exists under other methods", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("PUT", "/v1/:name", foundHandler)
+		router.HandleFunc("GET", "/v1/:name", foundHandler)
+		router.HandleFunc("DELETE", "/v1/:name", foundHandler)
+		router.HandleFunc("POST", "/v1", foundHandler)
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		request, err := http.NewRequest("POST", fmt.Sprintf("%s/v1/the_name", testServer.URL), nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(request)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+		g.Expect(resp.Header.Get("Allow")).To(Equal("DELETE, GET, HEAD, OPTIONS, PUT"))
+	})
+
+	t.Run("It returns a 405 when the request's method has no routes at all", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/v1/", foundHandler)
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		request, err := http.NewRequest("PATCH", fmt.Sprintf("%s/v1/anything", testServer.URL), nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(request)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+		g.Expect(resp.Header.Get("Allow")).To(Equal("GET, HEAD, OPTIONS"))
+	})
+
+	t.Run("It returns a 404 when the path does not exist under any method", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/v1/:name", foundHandler)
+		router.HandleFunc("PUT", "/v2", foundHandler)
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		request, err := http.NewRequest("POST", fmt.Sprintf("%s/v3", testServer.URL), nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(request)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		g.Expect(resp.Header.Get("Allow")).To(BeEmpty())
+	})
+}
+
+func TestRouter_AutomaticHEADAndOPTIONS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	getHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "get")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("get body"))
+	}
+
+	t.Run("Context HEAD requests", func(t *testing.T) {
+		t.Run("It serves the GET handler without writing the body", func(t *testing.T) {
+			router := New()
+			router.HandleFunc("GET", "/v1/:name", getHandler)
+
+			testServer := httptest.NewServer(router)
+			defer testServer.Close()
+
+			request, err := http.NewRequest("HEAD", fmt.Sprintf("%s/v1/the_name", testServer.URL), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			g.Expect(resp.Header.Get("X-Handler")).To(Equal("get"))
+
+			body, err := io.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(body).To(BeEmpty())
+		})
+
+		t.Run("It prefers an explicit HEAD handler", func(t *testing.T) {
+			router := New()
+			router.HandleFunc("GET", "/v1", getHandler)
+			router.HandleFunc("HEAD", "/v1", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Handler", "head")
+				w.WriteHeader(http.StatusOK)
+			})
+
+			testServer := httptest.NewServer(router)
+			defer testServer.Close()
+
+			request, err := http.NewRequest("HEAD", fmt.Sprintf("%s/v1", testServer.URL), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			g.Expect(resp.Header.Get("X-Handler")).To(Equal("head"))
+		})
+
+		t.Run("It can disable the GET fallback", func(t *testing.T) {
+			router := New()
+			router.DisableAutoHEAD = true
+			router.HandleFunc("GET", "/v1", getHandler)
+
+			testServer := httptest.NewServer(router)
+			defer testServer.Close()
+
+			request, err := http.NewRequest("HEAD", fmt.Sprintf("%s/v1", testServer.URL), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+			g.Expect(resp.Header.Get("Allow")).To(Equal("GET, OPTIONS"))
+		})
+	})
+
+	t.Run("Context OPTIONS requests", func(t *testing.T) {
+		t.Run("It answers with the Allow header for the path", func(t *testing.T) {
+			router := New()
+			router.HandleFunc("GET", "/v1/:name", getHandler)
+			router.HandleFunc("DELETE", "/v1/:name", getHandler)
+			router.HandleFunc("POST", "/v1", getHandler)
+
+			testServer := httptest.NewServer(router)
+			defer testServer.Close()
+
+			request, err := http.NewRequest("OPTIONS", fmt.Sprintf("%s/v1/the_name", testServer.URL), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			g.Expect(resp.Header.Get("Allow")).To(Equal("DELETE, GET, HEAD, OPTIONS"))
+		})
+
+		t.Run("It returns a 404 when the path is not registered", func(t *testing.T) {
+			router := New()
+			router.HandleFunc("GET", "/v1", getHandler)
+
+			testServer := httptest.NewServer(router)
+			defer testServer.Close()
+
+			request, err := http.NewRequest("OPTIONS", fmt.Sprintf("%s/v2", testServer.URL), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		t.Run("It prefers an explicit OPTIONS handler", func(t *testing.T) {
+			router := New()
+			router.HandleFunc("GET", "/v1", getHandler)
+			router.HandleFunc("OPTIONS", "/v1", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			testServer := httptest.NewServer(router)
+			defer testServer.Close()
+
+			request, err := http.NewRequest("OPTIONS", fmt.Sprintf("%s/v1", testServer.URL), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			g.Expect(resp.Header.Get("Allow")).To(BeEmpty())
+		})
+
+		t.Run("It can disable the automatic answer", func(t *testing.T) {
+			router := New()
+			router.DisableAutoOPTIONS = true
+			router.HandleFunc("GET", "/v1", getHandler)
+
+			testServer := httptest.NewServer(router)
+			defer testServer.Close()
+
+			request, err := http.NewRequest("OPTIONS", fmt.Sprintf("%s/v1", testServer.URL), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+			g.Expect(resp.Header.Get("Allow")).To(Equal("GET, HEAD"))
+		})
+	})
+}
+
+func TestRouter_NotFoundAndMethodNotAllowedHandlers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	foundHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	errorHandler := func(status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			w.Write([]byte(fmt.Sprintf(`{"expected":"%s","allow":"%s"}`, GetPartialMatch(r.Context()), w.Header().Get("Allow"))))
+		}
+	}
+
+	t.Run("It calls the NotFound handler with the closest partial match", func(t *testing.T) {
+		router := New()
+		router.NotFound = errorHandler(http.StatusNotFound)
+		router.HandleFunc("GET", "/v1/users/:id/posts", foundHandler)
+		router.HandleFunc("GET", "/v1/teams", foundHandler)
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		request, err := http.NewRequest("GET", fmt.Sprintf("%s/v1/users/42/comments", testServer.URL), nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(request)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		g.Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+
+		body, err := io.ReadAll(resp.Body)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(body)).To(Equal(`{"expected":"/v1/users/:id/","allow":""}`))
+	})
+
+	t.Run("It sets the root as the partial match when nothing past it matches", func(t *testing.T) {
+		router := New()
+		router.NotFound = errorHandler(http.StatusNotFound)
+		router.HandleFunc("GET", "/v1", foundHandler)
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		request, err := http.NewRequest("GET", fmt.Sprintf("%s/v2", testServer.URL), nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(request)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+		body, err := io.ReadAll(resp.Body)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(body)).To(Equal(`{"expected":"/","allow":""}`))
+	})
+
+	t.Run("It calls the MethodNotAllowed handler with the Allow header already set", func(t *testing.T) {
+		router := New()
+		router.MethodNotAllowed = errorHandler(http.StatusMethodNotAllowed)
+		router.HandleFunc("PUT", "/v1/:name", foundHandler)
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		request, err := http.NewRequest("POST", fmt.Sprintf("%s/v1/the_name", testServer.URL), nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(request)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+		g.Expect(resp.Header.Get("Allow")).To(Equal("OPTIONS, PUT"))
+
+		body, err := io.ReadAll(resp.Body)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(body)).To(Equal(`{"expected":"/v1/:name","allow":"OPTIONS, PUT"}`))
+	})
+}
+
+func TestRouter_Backtracking(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	var namedParameters = map[string]string{}
+	foundHandler := func(path string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			namedParameters = GetNamedParamters(r.Context())
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(path))
+		}
+	}
+
+	router := New()
+	router.HandleFunc("GET", "/users/", foundHandler("/users/"))
+	router.HandleFunc("GET", "/users/new", foundHandler("/users/new"))
+	router.HandleFunc("GET", "/users/new/edit", foundHandler("/users/new/edit"))
+	router.HandleFunc("GET", "/users/:id", foundHandler("/users/:id"))
+	router.HandleFunc("GET", "/users/:id/edit", foundHandler("/users/:id/edit"))
+	router.HandleFunc("GET", "/users/:id/posts", foundHandler("/users/:id/posts"))
+
+	testServer := httptest.NewServer(router)
+	defer testServer.Close()
+
+	tests := []struct {
+		path            string
+		expected        string
+		namedParameters map[string]string
+	}{
+		// static paths are always preferred
+		{path: "/users/new", expected: "/users/new", namedParameters: nil},
+		{path: "/users/new/edit", expected: "/users/new/edit", namedParameters: nil},
+		// named parameters are used when the static branch fails deeper down
+		{path: "/users/new/posts", expected: "/users/:id/posts", namedParameters: map[string]string{"id": "new"}},
+		{path: "/users/42", expected: "/users/:id", namedParameters: map[string]string{"id": "42"}},
+		{path: "/users/42/edit", expected: "/users/:id/edit", namedParameters: map[string]string{"id": "42"}},
+		// wildcards are used when every other branch fails
+		{path: "/users/new/unknown", expected: "/users/", namedParameters: nil},
+		{path: "/users/42/edit/unknown", expected: "/users/", namedParameters: nil},
+		{path: "/users/", expected: "/users/", namedParameters: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("It resolves %s to %s", test.path, test.expected), func(t *testing.T) {
+			namedParameters = map[string]string{}
+
+			request, err := http.NewRequest("GET", fmt.Sprintf("%s%s", testServer.URL, test.path), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			body, err := io.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(body)).To(Equal(test.expected))
+			g.Expect(namedParameters).To(Equal(test.namedParameters))
+		})
+	}
+}
+
+func TestRouter_PartialPrefixes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	foundHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	// build every combination of static and named segments, up to 3 levels deep
+	patterns := [][]string{{}}
+	for level := 1; level <= 3; level++ {
+		var nextPatterns [][]string
+		for _, pattern := range patterns {
+			nextPatterns = append(nextPatterns,
+				append(append([]string{}, pattern...), fmt.Sprintf("static%d", level)),
+				append(append([]string{}, pattern...), fmt.Sprintf(":named%d", level)),
+			)
+		}
+
+		patterns = nextPatterns
+		for _, pattern := range patterns {
+			path := ""
+			requestPath := ""
+			for _, segment := range pattern {
+				path += "/" + segment
+				if segment[0] == ':' {
+					requestPath += "/value"
+				} else {
+					requestPath += "/" + segment
+				}
+			}
+
+			t.Run(fmt.Sprintf("It only dispatches the full path for %s", path), func(t *testing.T) {
+				router := New()
+				router.HandleFunc("GET", path, foundHandler)
+
+				testServer := httptest.NewServer(router)
+				defer testServer.Close()
+
+				// the full path is found
+				request, err := http.NewRequest("GET", fmt.Sprintf("%s%s", testServer.URL, requestPath), nil)
+				g.Expect(err).ToNot(HaveOccurred())
+
+				resp, err := client.Do(request)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+				// every prefix, with and without a trailing '/' is not found
+				for index := 1; index < len(requestPath); index++ {
+					if requestPath[index] != '/' {
+						continue
+					}
+
+					for _, prefix := range []string{requestPath[:index], requestPath[:index+1]} {
+						for _, method := range []string{"GET", "POST"} {
+							request, err := http.NewRequest(method, fmt.Sprintf("%s%s", testServer.URL, prefix), nil)
+							g.Expect(err).ToNot(HaveOccurred())
+
+							resp, err := client.Do(request)
+							g.Expect(err).ToNot(HaveOccurred())
+							g.Expect(resp.StatusCode).To(Equal(http.StatusNotFound), fmt.Sprintf("%s %s", method, prefix))
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRouter_CatchAllParameters(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	var namedParameters = map[string]string{}
+	foundHandler := func(path string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			namedParameters = GetNamedParamters(r.Context())
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(path))
+		}
+	}
+
+	t.Run("It panics if the catch all parameter is not the last path", func(t *testing.T) {
+		router := New()
+		g.Expect(func() { router.HandleFunc("GET", "/files/*filepath/edit", foundHandler("")) }).To(Panic())
+	})
+
+	t.Run("It panics if the catch all parameter has no name", func(t *testing.T) {
+		router := New()
+		g.Expect(func() { router.HandleFunc("GET", "/files/*", foundHandler("")) }).To(Panic())
+	})
+
+	t.Run("It panics if the catch all parameter reuses a name", func(t *testing.T) {
+		router := New()
+		g.Expect(func() { router.HandleFunc("GET", "/:name/*name", foundHandler("")) }).To(Panic())
+	})
+
+	t.Run("Context resolving requests", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/", foundHandler("/"))
+		router.HandleFunc("GET", "/files/*filepath", foundHandler("/files/*filepath"))
+		router.HandleFunc("GET", "/files/readme", foundHandler("/files/readme"))
+		router.HandleFunc("GET", "/files/:name/info", foundHandler("/files/:name/info"))
+		router.HandleFunc("GET", "/blobs/:bucket/*key", foundHandler("/blobs/:bucket/*key"))
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		tests := []struct {
+			path            string
+			expected        string
+			namedParameters map[string]string
+		}{
+			{path: "/files/a/b/c.txt", expected: "/files/*filepath", namedParameters: map[string]string{"filepath": "a/b/c.txt"}},
+			{path: "/files/a/", expected: "/files/*filepath", namedParameters: map[string]string{"filepath": "a/"}},
+			{path: "/files/", expected: "/files/*filepath", namedParameters: map[string]string{"filepath": ""}},
+			{path: "/files/readme", expected: "/files/readme", namedParameters: nil},
+			{path: "/files/readme/more", expected: "/files/*filepath", namedParameters: map[string]string{"filepath": "readme/more"}},
+			{path: "/files/a/info", expected: "/files/:name/info", namedParameters: map[string]string{"name": "a"}},
+			{path: "/files", expected: "/", namedParameters: nil},
+			{path: "/blobs/images/2023/cat.png", expected: "/blobs/:bucket/*key", namedParameters: map[string]string{"bucket": "images", "key": "2023/cat.png"}},
+		}
+
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("It resolves %s to %s", test.path, test.expected), func(t *testing.T) {
+				namedParameters = map[string]string{}
+
+				request, err := http.NewRequest("GET", fmt.Sprintf("%s%s", testServer.URL, test.path), nil)
+				g.Expect(err).ToNot(HaveOccurred())
+
+				resp, err := client.Do(request)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+				body, err := io.ReadAll(resp.Body)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(string(body)).To(Equal(test.expected))
+				g.Expect(namedParameters).To(Equal(test.namedParameters))
+			})
+		}
+	})
+}
+
+func TestRouter_WildcardsBeneathNamedParameters(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	var namedParameters = map[string]string{}
+	foundHandler := func(path string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			namedParameters = GetNamedParamters(r.Context())
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(path))
+		}
+	}
+
+	type test struct {
+		path            string
+		expected        string
+		namedParameters map[string]string
+	}
+
+	run := func(t *testing.T, router *Router, tests []test) {
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		for _, test := range tests {
+			namedParameters = map[string]string{}
+
+			request, err := http.NewRequest("GET", fmt.Sprintf("%s%s", testServer.URL, test.path), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := client.Do(request)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK), test.path)
+
+			body, err := io.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(body)).To(Equal(test.expected), test.path)
+			g.Expect(namedParameters).To(Equal(test.namedParameters), test.path)
+		}
+	}
+
+	t.Run("It matches the named parameter on its own and anything beneath it", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/:tenant/", foundHandler("/:tenant/"))
+
+		run(t, router, []test{
+			{path: "/acme", expected: "/:tenant/", namedParameters: map[string]string{"tenant": "acme"}},
+			{path: "/acme/", expected: "/:tenant/", namedParameters: map[string]string{"tenant": "acme"}},
+			{path: "/acme/anything/deep", expected: "/:tenant/", namedParameters: map[string]string{"tenant": "acme"}},
+		})
+	})
+
+	t.Run("It prefers exact named handlers", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/:tenant", foundHandler("/:tenant"))
+		router.HandleFunc("GET", "/:tenant/", foundHandler("/:tenant/"))
+		router.HandleFunc("GET", "/:tenant/users", foundHandler("/:tenant/users"))
+
+		run(t, router, []test{
+			{path: "/acme", expected: "/:tenant", namedParameters: map[string]string{"tenant": "acme"}},
+			{path: "/acme/", expected: "/:tenant/", namedParameters: map[string]string{"tenant": "acme"}},
+			{path: "/acme/users", expected: "/:tenant/users", namedParameters: map[string]string{"tenant": "acme"}},
+			{path: "/acme/users/42", expected: "/:tenant/", namedParameters: map[string]string{"tenant": "acme"}},
+		})
+	})
+
+	t.Run("It is preferred over wildcards closer to the root", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/", foundHandler("/"))
+		router.HandleFunc("GET", "/static", foundHandler("/static"))
+		router.HandleFunc("GET", "/:tenant/", foundHandler("/:tenant/"))
+
+		run(t, router, []test{
+			{path: "/static", expected: "/static", namedParameters: nil},
+			{path: "/static/more", expected: "/:tenant/", namedParameters: map[string]string{"tenant": "static"}},
+			{path: "/acme", expected: "/:tenant/", namedParameters: map[string]string{"tenant": "acme"}},
+			{path: "/", expected: "/", namedParameters: nil},
+		})
+	})
+
+	t.Run("It also applies to catch all parameters beneath named parameters", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/:tenant/*rest", foundHandler("/:tenant/*rest"))
+
+		run(t, router, []test{
+			{path: "/acme", expected: "/:tenant/*rest", namedParameters: map[string]string{"tenant": "acme", "rest": ""}},
+			{path: "/acme/a/b", expected: "/:tenant/*rest", namedParameters: map[string]string{"tenant": "acme", "rest": "a/b"}},
+		})
+	})
+}
+
+func TestRouter_NamedParameterConstraints(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := &http.Client{}
+
+	var namedParameters = map[string]string{}
+	foundHandler := func(path string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			namedParameters = GetNamedParamters(r.Context())
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(path))
+		}
+	}
+
+	t.Run("It panics on an unknown constraint", func(t *testing.T) {
+		router := New()
+		g.Expect(func() { router.HandleFunc("GET", "/users/:id<unknown>", foundHandler("")) }).To(Panic())
+	})
+
+	t.Run("It panics on an invalid regular expression", func(t *testing.T) {
+		router := New()
+		g.Expect(func() { router.HandleFunc("GET", "/users/:id{[0-9}", foundHandler("")) }).To(Panic())
+	})
+
+	t.Run("It panics on a constrained catch all parameter", func(t *testing.T) {
+		router := New()
+		g.Expect(func() { router.HandleFunc("GET", "/files/*filepath<int>", foundHandler("")) }).To(Panic())
+	})
+
+	t.Run("It panics when the same constraint is registered with different names", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users/:id<int>", foundHandler(""))
+		g.Expect(func() { router.HandleFunc("GET", "/users/:userID<int>", foundHandler("")) }).To(Panic())
+	})
+
+	t.Run("Context resolving requests", func(t *testing.T) {
+		router := New(WithConstraints(Constraints{
+			"even": func(value string) bool {
+				return strings.Trim(value, "0123456789") == "" && (value[len(value)-1]-'0')%2 == 0
+			},
+		}))
+		router.HandleFunc("GET", "/users/:name", foundHandler("/users/:name"))
+		router.HandleFunc("GET", "/users/:id<int>", foundHandler("/users/:id<int>"))
+		router.HandleFunc("GET", "/users/:id<int>/posts", foundHandler("/users/:id<int>/posts"))
+		router.HandleFunc("GET", "/users/:uuid<uuid>", foundHandler("/users/:uuid<uuid>"))
+		router.HandleFunc("GET", "/orders/:code{[A-Z]{3}-[0-9]+}", foundHandler("/orders/:code{[A-Z]{3}-[0-9]+}"))
+		router.HandleFunc("GET", "/pages/:page<even>", foundHandler("/pages/:page<even>"))
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		tests := []struct {
+			path            string
+			status          int
+			expected        string
+			namedParameters map[string]string
+		}{
+			{path: "/users/42", status: http.StatusOK, expected: "/users/:id<int>", namedParameters: map[string]string{"id": "42"}},
+			{path: "/users/-42", status: http.StatusOK, expected: "/users/:id<int>", namedParameters: map[string]string{"id": "-42"}},
+			{path: "/users/42/posts", status: http.StatusOK, expected: "/users/:id<int>/posts", namedParameters: map[string]string{"id": "42"}},
+			{path: "/users/bob", status: http.StatusOK, expected: "/users/:name", namedParameters: map[string]string{"name": "bob"}},
+			{path: "/users/6ba7b810-9dad-11d1-80b4-00c04fd430c8", status: http.StatusOK, expected: "/users/:uuid<uuid>", namedParameters: map[string]string{"uuid": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}},
+			{path: "/users/bob/posts", status: http.StatusNotFound},
+			{path: "/orders/ABC-123", status: http.StatusOK, expected: "/orders/:code{[A-Z]{3}-[0-9]+}", namedParameters: map[string]string{"code": "ABC-123"}},
+			{path: "/orders/ABC-123x", status: http.StatusNotFound},
+			{path: "/pages/4", status: http.StatusOK, expected: "/pages/:page<even>", namedParameters: map[string]string{"page": "4"}},
+			{path: "/pages/3", status: http.StatusNotFound},
+		}
+
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("It resolves %s", test.path), func(t *testing.T) {
+				namedParameters = map[string]string{}
+
+				request, err := http.NewRequest("GET", fmt.Sprintf("%s%s", testServer.URL, test.path), nil)
+				g.Expect(err).ToNot(HaveOccurred())
+
+				resp, err := client.Do(request)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(resp.StatusCode).To(Equal(test.status))
+
+				if test.status == http.StatusOK {
+					body, err := io.ReadAll(resp.Body)
+					g.Expect(err).ToNot(HaveOccurred())
+					g.Expect(string(body)).To(Equal(test.expected))
+					g.Expect(namedParameters).To(Equal(test.namedParameters))
+				}
+			})
+		}
+	})
+}
+
+func TestRouter_Remove(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	patternHandler := func(w http.ResponseWriter, r *http.Request) {
+		info, _ := GetRouteInfo(r.Context())
+		_, _ = w.Write([]byte(info.Pattern))
+	}
+
+	serve := func(router *Router, method, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		return recorder
+	}
+
+	t.Run("It reports false when nothing was registered", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users/:id", patternHandler)
+
+		g.Expect(router.Remove("POST", "/users/:id")).To(BeFalse())
+		g.Expect(router.Remove("GET", "/users")).To(BeFalse())
+		g.Expect(router.Remove("GET", "/users/:id/posts")).To(BeFalse())
+		g.Expect(router.Remove("GET", "/users/:userID")).To(BeFalse())
+		g.Expect(router.Remove("GET", "/users/:id<int>")).To(BeFalse())
+		g.Expect(router.Remove("GET", "/users/")).To(BeFalse())
+		g.Expect(router.Remove("GET", "/users/*rest")).To(BeFalse())
+
+		g.Expect(serve(router, "GET", "/users/42").Body.String()).To(Equal("/users/:id"))
+	})
+
+	t.Run("It removes handlers, wildcards and catch all parameters", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users", patternHandler)
+		router.HandleFunc("GET", "/users/:id", patternHandler)
+		router.HandleFunc("GET", "/users/:id<int>", patternHandler)
+		router.HandleFunc("GET", "/files/*filepath", patternHandler)
+		router.HandleFunc("GET", "/", patternHandler)
+
+		g.Expect(router.Remove("GET", "/users/:id<int>")).To(BeTrue())
+		g.Expect(serve(router, "GET", "/users/42").Body.String()).To(Equal("/users/:id"))
+
+		g.Expect(router.Remove("GET", "/files/*filepath")).To(BeTrue())
+		g.Expect(serve(router, "GET", "/files/a/b").Body.String()).To(Equal("/"))
+
+		g.Expect(router.Remove("GET", "/")).To(BeTrue())
+		g.Expect(serve(router, "GET", "/files/a/b").Code).To(Equal(http.StatusNotFound))
+
+		g.Expect(router.Remove("GET", "/users/:id")).To(BeTrue())
+		g.Expect(serve(router, "GET", "/users/42").Code).To(Equal(http.StatusNotFound))
+		g.Expect(serve(router, "GET", "/users").Body.String()).To(Equal("/users"))
+
+		g.Expect(router.Remove("GET", "/users/:id")).To(BeFalse())
+	})
+
+	t.Run("It prunes the routes that are left empty", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/tenants/:tenant/users/:id", patternHandler)
+		router.HandleFunc("GET", "/tenants/:tenant<int>/settings", patternHandler)
+		router.HandleFunc("POST", "/tenants", patternHandler)
+
+		g.Expect(router.Remove("GET", "/tenants/:tenant/users/:id")).To(BeTrue())
+		tenants := router.table.Load().routes["GET"].urlChildren["/"].urlChildren["tenants"].urlChildren["/"]
+		g.Expect(tenants.namedChildren).To(HaveLen(1))
+		g.Expect(tenants.namedChildren[0].constraint).To(Equal("<int>"))
+
+		g.Expect(router.Remove("GET", "/tenants/:tenant<int>/settings")).To(BeTrue())
+		g.Expect(router.table.Load().routes).ToNot(HaveKey("GET"))
+
+		// the partial match no longer finds the removed routes
+		recorder := serve(router, "GET", "/tenants/42/settings")
+		g.Expect(recorder.Code).To(Equal(http.StatusNotFound))
+	})
+
+	t.Run("It removes the name of the route", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users/:id", patternHandler, WithName("user"))
+		router.HandleFunc("DELETE", "/users/:id", patternHandler, WithName("user"))
+
+		g.Expect(router.Remove("GET", "/users/:id")).To(BeTrue())
+		url, err := router.URL("user", "id", "42")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(url).To(Equal("/users/42"))
+
+		g.Expect(router.Remove("DELETE", "/users/:id")).To(BeTrue())
+		_, err = router.URL("user", "id", "42")
+		g.Expect(err).To(MatchError(ErrUnknownRoute))
+
+		router.HandleFunc("GET", "/people/:id", patternHandler, WithName("user"))
+		url, err = router.URL("user", "id", "42")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(url).To(Equal("/people/42"))
+	})
+}
+
+func TestRouter_Add(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	foundHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("It registers routes that can be served", func(t *testing.T) {
+		router := New()
+		g.Expect(router.Add("GET", "/users/:id<int>", foundHandler)).To(Succeed())
+		g.Expect(router.Add("M-SEARCH", "/users/:id<int>", foundHandler)).To(Succeed())
+		g.Expect(router.Add("GET", "/names/:name{[a-z ]+}/", foundHandler)).To(Succeed())
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/users/42", nil))
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	t.Run("It returns typed errors without changing the router", func(t *testing.T) {
+		router := New()
+		g.Expect(router.Add("GET", "/users/:id", foundHandler, WithName("user"))).To(Succeed())
+
+		tests := []struct {
+			name     string
+			method   string
+			path     string
+			handler  http.Handler
+			options  []RouteOption
+			expected error
+		}{
+			{name: "an empty method", method: "", path: "/other", handler: foundHandler, expected: ErrInvalidMethod},
+			{name: "a method with a space", method: "GET POST", path: "/other", handler: foundHandler, expected: ErrInvalidMethod},
+			{name: "an empty path", method: "GET", path: "", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "a path without a leading slash", method: "GET", path: "users", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "a path with an empty path", method: "GET", path: "/a//b", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "a path that ends in an empty path", method: "GET", path: "/a//", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "a path with a space", method: "GET", path: "/a b", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "a named parameter with a space", method: "GET", path: "/other/:first name", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "a nil handler", method: "GET", path: "/other", handler: nil, expected: ErrInvalidHandler},
+			{name: "a nil handler func", method: "GET", path: "/other", handler: http.HandlerFunc(nil), expected: ErrInvalidHandler},
+			{name: "a catch all that is not last", method: "GET", path: "/files/*filepath/other", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "a catch all with a constraint", method: "GET", path: "/files/*filepath<int>", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "a named parameter without a name", method: "GET", path: "/other/:", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "an invalid regular expression", method: "GET", path: "/other/:id{[0-9+}", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "an unknown constraint", method: "GET", path: "/other/:id<even>", handler: foundHandler, expected: ErrInvalidPattern},
+			{name: "a duplicate route", method: "GET", path: "/users/:id", handler: foundHandler, expected: ErrDuplicateRoute},
+			{name: "a named parameter used twice", method: "GET", path: "/other/:id/:id", handler: foundHandler, expected: ErrConflictingParams},
+			{name: "different named parameters for the same route", method: "GET", path: "/users/:userID", handler: foundHandler, expected: ErrConflictingParams},
+			{name: "a name used by another pattern", method: "GET", path: "/other", handler: foundHandler, options: []RouteOption{WithName("user")}, expected: ErrDuplicateName},
+		}
+
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("It errors on %s", test.name), func(t *testing.T) {
+				err := router.Add(test.method, test.path, test.handler, test.options...)
+				g.Expect(err).To(MatchError(test.expected))
+
+				routeErr, ok := err.(*RouteError)
+				g.Expect(ok).To(BeTrue())
+				g.Expect(routeErr.Method).To(Equal(test.method))
+				g.Expect(routeErr.Pattern).To(Equal(test.path))
+				g.Expect(routeErr.Error()).To(ContainSubstring(test.path))
+				g.Expect(routeErr.Source).To(ContainSubstring("router_test.go:"))
+			})
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/other", nil))
+		g.Expect(recorder.Code).To(Equal(http.StatusNotFound))
+
+		url, err := router.URL("user", "id", "42")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(url).To(Equal("/users/42"))
+	})
+
+	t.Run("It keeps overwriting duplicate routes with HandleFunc", func(t *testing.T) {
+		router := New()
+		g.Expect(router.Add("GET", "/users", foundHandler)).To(Succeed())
+
+		router.HandleFunc("GET", "/users", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/users", nil))
+		g.Expect(recorder.Code).To(Equal(http.StatusAccepted))
+	})
+
+	t.Run("It adds routes beneath a group's prefix", func(t *testing.T) {
+		router := New()
+		group := router.Group("/v1")
+		g.Expect(group.Add("GET", "/users", foundHandler)).To(Succeed())
+		g.Expect(group.Add("GET", "/users", foundHandler)).To(MatchError(ErrDuplicateRoute))
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/users", nil))
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+}
+
+func TestRouter_Strict(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	foundHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	t.Run("It panics on exact duplicates, naming both registrations", func(t *testing.T) {
+		router := New(WithStrict())
+		router.HandleFunc("GET", "/users/:id", foundHandler)
+
+		var recovered any
+		func() {
+			defer func() { recovered = recover() }()
+			router.HandleFunc("GET", "/users/:id", foundHandler)
+		}()
+
+		err, ok := recovered.(error)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(MatchError(ErrDuplicateRoute))
+		g.Expect(strings.Count(err.Error(), "router_test.go:")).To(Equal(2))
+		g.Expect(strings.Count(err.Error(), `"/users/:id"`)).To(Equal(2))
+	})
+
+	t.Run("It still allows the same pattern for different methods", func(t *testing.T) {
+		router := New(WithStrict())
+		router.HandleFunc("GET", "/users/:id", foundHandler)
+		router.HandleFunc("DELETE", "/users/:id", foundHandler)
+	})
+
+	t.Run("It rejects a wildcard and a catch all parameter for the same path", func(t *testing.T) {
+		router := New(WithStrict())
+		g.Expect(router.Add("GET", "/files/", http.HandlerFunc(foundHandler))).To(Succeed())
+		g.Expect(router.Add("GET", "/assets/*filepath", http.HandlerFunc(foundHandler))).To(Succeed())
+
+		err := router.Add("GET", "/files/*filepath", http.HandlerFunc(foundHandler))
+		g.Expect(err).To(MatchError(ErrAmbiguousRoute))
+		g.Expect(err.Error()).To(ContainSubstring(`"/files/*filepath"`))
+		g.Expect(err.Error()).To(ContainSubstring(`"/files/"`))
+		g.Expect(strings.Count(err.Error(), "router_test.go:")).To(Equal(2))
+
+		err = router.Add("GET", "/assets/", http.HandlerFunc(foundHandler))
+		g.Expect(err).To(MatchError(ErrAmbiguousRoute))
+		g.Expect(err.Error()).To(ContainSubstring(`"/assets/*filepath"`))
+	})
+
+	t.Run("It rejects two catch all parameters for the same path as ambiguous", func(t *testing.T) {
+		router := New(WithStrict())
+		g.Expect(router.Add("GET", "/files/*filepath", http.HandlerFunc(foundHandler))).To(Succeed())
+		g.Expect(router.Add("GET", "/:tenant/*filepath", http.HandlerFunc(foundHandler))).To(Succeed())
+
+		err := router.Add("GET", "/files/*path", http.HandlerFunc(foundHandler))
+		g.Expect(err).To(MatchError(ErrAmbiguousRoute))
+		g.Expect(err.Error()).To(ContainSubstring(`"/files/*filepath"`))
+		g.Expect(strings.Count(err.Error(), "router_test.go:")).To(Equal(2))
+
+		err = router.Add("GET", "/:name/*filepath", http.HandlerFunc(foundHandler))
+		g.Expect(err).To(MatchError(ErrAmbiguousRoute))
+		g.Expect(err.Error()).To(ContainSubstring(`"/:tenant/*filepath"`))
+
+		// without strict mode, the names are still a conflict
+		router = New()
+		g.Expect(router.Add("GET", "/files/*filepath", http.HandlerFunc(foundHandler))).To(Succeed())
+		g.Expect(router.Add("GET", "/files/*path", http.HandlerFunc(foundHandler))).To(MatchError(ErrConflictingParams))
+	})
+
+	t.Run("It allows named parameters with overlapping constraints for the same path", func(t *testing.T) {
+		router := New(WithStrict())
+		g.Expect(router.Add("GET", "/users/:id<int>", http.HandlerFunc(foundHandler))).To(Succeed())
+		g.Expect(router.Add("GET", "/users/:id{[0-9a-f]+}", http.HandlerFunc(foundHandler))).To(Succeed())
+		g.Expect(router.Add("GET", "/users/:name", http.HandlerFunc(foundHandler))).To(Succeed())
+	})
+
+	t.Run("It reports the caller's location for routes registered through a group", func(t *testing.T) {
+		router := New(WithStrict())
+		group := router.Group("/v1")
+		group.HandleFunc("GET", "/users", foundHandler)
+
+		err := group.Add("GET", "/users", http.HandlerFunc(foundHandler))
+		g.Expect(err).To(MatchError(ErrDuplicateRoute))
+		g.Expect(strings.Count(err.Error(), "router_test.go:")).To(Equal(2))
+		g.Expect(err.Error()).ToNot(ContainSubstring("group.go"))
+	})
+
+	t.Run("It allows wildcards and catch all parameters for the same path when not strict", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/files/", foundHandler)
+		router.HandleFunc("GET", "/files/*filepath", foundHandler)
+	})
+}
@@ -0,0 +1,134 @@
+package urlrouter
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of an OpenAPI 3 schema object used to describe named parameters,
+// request bodies and responses
+type Schema struct {
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Pattern              string             `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Enum                 []any              `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SchemaOf describes the JSON encoding of a value's type. Struct fields are named by their
+// `json` tag, and are required unless they are tagged with omitempty. A type that refers to
+// itself is described as an object where it repeats
+func SchemaOf(value any) *Schema {
+	if value == nil {
+		return &Schema{}
+	}
+
+	return schemaOf(reflect.TypeOf(value), map[reflect.Type]bool{})
+}
+
+func schemaOf(valueType reflect.Type, describing map[reflect.Type]bool) *Schema {
+	for valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+	}
+
+	switch {
+	case valueType == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case valueType.Implements(textMarshalerType) || reflect.PtrTo(valueType).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch valueType.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		minimum := float64(0)
+		return &Schema{Type: "integer", Minimum: &minimum}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if valueType.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: schemaOf(valueType.Elem(), describing)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(valueType.Elem(), describing)}
+	case reflect.Struct:
+		if describing[valueType] {
+			return &Schema{Type: "object"}
+		}
+
+		describing[valueType] = true
+		defer delete(describing, valueType)
+
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		addFields(schema, valueType, describing)
+		return schema
+	default:
+		// interfaces can hold any value
+		return &Schema{}
+	}
+}
+
+// addFields adds the exported fields of a struct to an object's properties. Embedded structs
+// without a `json` tag have their fields added to the same object, like encoding/json
+func addFields(schema *Schema, structType reflect.Type, describing map[reflect.Type]bool) {
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Pointer {
+				embeddedType = embeddedType.Elem()
+			}
+
+			if embeddedType.Kind() == reflect.Struct {
+				addFields(schema, embeddedType, describing)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = schemaOf(field.Type, describing)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
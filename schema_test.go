@@ -0,0 +1,77 @@
+package urlrouter
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type schemaAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city,omitempty"`
+}
+
+type schemaAudit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type schemaUser struct {
+	schemaAudit
+
+	ID       int64             `json:"id"`
+	Name     string            `json:"name"`
+	Age      uint8             `json:"age,omitempty"`
+	Score    float64           `json:"score,omitempty"`
+	Admin    bool              `json:"admin"`
+	Tags     []string          `json:"tags,omitempty"`
+	Avatar   []byte            `json:"avatar,omitempty"`
+	Address  *schemaAddress    `json:"address,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Extra    any               `json:"extra,omitempty"`
+	Friends  []*schemaUser     `json:"friends,omitempty"`
+	Password string            `json:"-"`
+	Nickname string
+	internal string
+}
+
+func TestSchemaOf(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("It describes basic types", func(t *testing.T) {
+		g.Expect(SchemaOf(nil)).To(Equal(&Schema{}))
+		g.Expect(SchemaOf("")).To(Equal(&Schema{Type: "string"}))
+		g.Expect(SchemaOf(true)).To(Equal(&Schema{Type: "boolean"}))
+		g.Expect(SchemaOf(int32(0))).To(Equal(&Schema{Type: "integer", Format: "int32"}))
+		g.Expect(SchemaOf(0)).To(Equal(&Schema{Type: "integer", Format: "int64"}))
+		g.Expect(SchemaOf(0.5)).To(Equal(&Schema{Type: "number", Format: "double"}))
+		g.Expect(SchemaOf(time.Time{})).To(Equal(&Schema{Type: "string", Format: "date-time"}))
+		g.Expect(SchemaOf([]int32{})).To(Equal(&Schema{Type: "array", Items: &Schema{Type: "integer", Format: "int32"}}))
+	})
+
+	t.Run("It describes structs by their json tags", func(t *testing.T) {
+		schema := SchemaOf(&schemaUser{})
+
+		g.Expect(schema.Type).To(Equal("object"))
+		g.Expect(schema.Required).To(Equal([]string{"created_at", "id", "name", "admin", "Nickname"}))
+		g.Expect(schema.Properties).To(HaveLen(13))
+		g.Expect(schema.Properties).ToNot(HaveKey("Password"))
+		g.Expect(schema.Properties).ToNot(HaveKey("internal"))
+
+		g.Expect(schema.Properties["created_at"]).To(Equal(&Schema{Type: "string", Format: "date-time"}))
+		g.Expect(*schema.Properties["age"].Minimum).To(Equal(float64(0)))
+		g.Expect(schema.Properties["avatar"]).To(Equal(&Schema{Type: "string", Format: "byte"}))
+		g.Expect(schema.Properties["labels"]).To(Equal(&Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}))
+		g.Expect(schema.Properties["extra"]).To(Equal(&Schema{}))
+		g.Expect(schema.Properties["address"]).To(Equal(&Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"street": {Type: "string"}, "city": {Type: "string"}},
+			Required:   []string{"street"},
+		}))
+	})
+
+	t.Run("It stops describing a type that refers to itself", func(t *testing.T) {
+		schema := SchemaOf(schemaUser{})
+		g.Expect(schema.Properties["friends"]).To(Equal(&Schema{Type: "array", Items: &Schema{Type: "object"}}))
+	})
+}
@@ -0,0 +1,163 @@
+package urlrouter
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// table is an immutable snapshot of everything registered on a Router. Registering a route
+// copies the parts of the table that change and then swaps in the new table, so requests
+// that are being served never see a partially registered route
+type table struct {
+	routes routes
+	hosts  []*host
+	names  map[string]*endpoint
+
+	// middleware holds a handler for each call to Use, which wraps the middleware of that call
+	// around the handler of the next call, or the matched handler for the last call
+	middleware []http.Handler
+}
+
+// clone returns a copy of the table with its own routes. The names, middleware and the routes
+// of each host are shared, so they must be copied before they are changed
+func (t *table) clone() *table {
+	cloned := &table{
+		routes:     make(routes, len(t.routes)),
+		hosts:      append([]*host(nil), t.hosts...),
+		names:      t.names,
+		middleware: t.middleware,
+	}
+
+	for method, route := range t.routes {
+		cloned.routes[method] = route
+	}
+
+	return cloned
+}
+
+// add the endpoint to the table's routes and names, returning a RouteError when it can't be
+// registered. See route.addUrl for overwrite and strict
+func (t *table) add(newEndpoint *endpoint, constraints Constraints, overwrite, strict bool) error {
+	method, path := newEndpoint.method, newEndpoint.pattern
+
+	// the same name can be used for multiple methods, as long as the pattern is the same
+	if named, ok := t.names[newEndpoint.name]; ok && newEndpoint.name != "" && named.pattern != newEndpoint.pattern {
+		return &RouteError{Method: method, Pattern: path, Source: newEndpoint.source, Err: fmt.Errorf("%w: %q is used by route %q at %s", ErrDuplicateName, newEndpoint.name, named.pattern, named.source)}
+	}
+
+	tree, err := t.hostTree(newEndpoint.host, constraints)
+	if err != nil {
+		return &RouteError{Method: method, Pattern: path, Source: newEndpoint.source, Err: err}
+	}
+
+	foundRoute, ok := tree[method]
+	if !ok {
+		foundRoute = &route{name: method}
+	}
+
+	foundRoute, err = foundRoute.addUrl(newEndpoint, constraints, overwrite, strict)
+	if err != nil {
+		return &RouteError{Method: method, Pattern: path, Source: newEndpoint.source, Err: err}
+	}
+
+	tree[method] = foundRoute
+
+	if newEndpoint.name != "" {
+		names := make(map[string]*endpoint, len(t.names)+1)
+		for name, namedEndpoint := range t.names {
+			names[name] = namedEndpoint
+		}
+
+		names[newEndpoint.name] = newEndpoint
+		t.names = names
+	}
+
+	return nil
+}
+
+// remove the endpoints registered for the method and pattern from the routes of the host,
+// returning the removed endpoints. A host left without any routes is removed
+func (t *table) remove(host, method, pattern string) candidates {
+	tree, index := t.routes, -1
+	if host != "" {
+		for known := range t.hosts {
+			if t.hosts[known].pattern == host {
+				index = known
+			}
+		}
+
+		if index < 0 {
+			return nil
+		}
+
+		tree = t.hosts[index].routes
+	}
+
+	foundRoute, ok := tree[method]
+	if !ok {
+		return nil
+	}
+
+	remaining, removed := foundRoute.removeUrl(pattern)
+	if len(removed) == 0 {
+		return nil
+	}
+
+	// the host is already registered, so this only copies its routes
+	tree, _ = t.hostTree(host, nil)
+	if remaining == nil {
+		delete(tree, method)
+	} else {
+		tree[method] = remaining
+	}
+
+	if index >= 0 && len(tree) == 0 {
+		t.hosts = append(t.hosts[:index], t.hosts[index+1:]...)
+	}
+
+	for _, removedEndpoint := range removed {
+		if removedEndpoint.name != "" && t.names[removedEndpoint.name] == removedEndpoint {
+			t.removeName(removedEndpoint.name)
+		}
+	}
+
+	return removed
+}
+
+// removeName removes a route's name, unless the same route is still registered with the
+// name under another method
+func (t *table) removeName(name string) {
+	names := make(map[string]*endpoint, len(t.names))
+	for knownName, namedEndpoint := range t.names {
+		if knownName != name {
+			names[knownName] = namedEndpoint
+		}
+	}
+
+	pattern := t.names[name].pattern
+	for _, tree := range t.trees() {
+		for _, route := range tree {
+			if namedEndpoint := route.find(pattern); namedEndpoint != nil && namedEndpoint.name == name {
+				names[name] = namedEndpoint
+			}
+		}
+	}
+
+	t.names = names
+}
+
+// update applies a change to a copy of the router's table, and then swaps in the copy.
+// Updates are serialized, so concurrent registrations never lose each other's changes.
+// When the change errors or panics, the router keeps serving with the current table
+func (router *Router) update(change func(updated *table) error) error {
+	router.lock.Lock()
+	defer router.lock.Unlock()
+
+	updated := router.table.Load().clone()
+	if err := change(updated); err != nil {
+		return err
+	}
+
+	router.table.Store(updated)
+	return nil
+}
@@ -0,0 +1,127 @@
+package urlrouter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRouter_ConcurrentRegistration(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	patternHandler := func(w http.ResponseWriter, r *http.Request) {
+		info, _ := GetRouteInfo(r.Context())
+		_, _ = w.Write([]byte(info.Pattern))
+	}
+
+	t.Run("It serves requests while routes and middleware are being added", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users/:id", patternHandler)
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+		client := testServer.Client()
+
+		// assertions can only fail the test from the test's goroutine, so the results of the
+		// requests are collected and checked once every goroutine is done
+		type result struct {
+			status int
+			body   string
+			err    error
+		}
+		results := make(chan result, 10)
+
+		wg := sync.WaitGroup{}
+		for index := 0; index < 10; index++ {
+			wg.Add(2)
+
+			go func(index int) {
+				defer wg.Done()
+				router.HandleFunc("GET", fmt.Sprintf("/users/:id/posts%d", index), patternHandler, WithName(fmt.Sprintf("posts%d", index)))
+				router.HandleFunc("POST", fmt.Sprintf("/users/:id<int>/posts%d", index), patternHandler)
+				router.Use(func(next http.Handler) http.Handler { return next })
+			}(index)
+
+			go func() {
+				defer wg.Done()
+				resp, err := client.Get(testServer.URL + "/users/42")
+				if err != nil {
+					results <- result{err: err}
+					return
+				}
+				defer resp.Body.Close()
+
+				body, err := io.ReadAll(resp.Body)
+				results <- result{status: resp.StatusCode, body: string(body), err: err}
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		for result := range results {
+			g.Expect(result.err).ToNot(HaveOccurred())
+			g.Expect(result.status).To(Equal(http.StatusOK))
+			g.Expect(result.body).To(Equal("/users/:id"))
+		}
+
+		for index := 0; index < 10; index++ {
+			for _, method := range []string{"GET", "POST"} {
+				request := httptest.NewRequest(method, fmt.Sprintf("/users/42/posts%d", index), nil)
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, request)
+				g.Expect(recorder.Code).To(Equal(http.StatusOK))
+			}
+
+			url, err := router.URL(fmt.Sprintf("posts%d", index), "id", "7")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(url).To(Equal(fmt.Sprintf("/users/7/posts%d", index)))
+		}
+
+		g.Expect(router.table.Load().middleware).To(HaveLen(10))
+	})
+
+	t.Run("It keeps serving the previous routes when a registration panics", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users/:id", patternHandler)
+
+		g.Expect(func() { router.HandleFunc("GET", "/users/:name/posts/:name", patternHandler) }).To(Panic())
+		g.Expect(func() { router.HandleFunc("GET", "/users/:userID", patternHandler) }).To(Panic())
+
+		request := httptest.NewRequest("GET", "/users/42/posts/7", nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		g.Expect(recorder.Code).To(Equal(http.StatusNotFound))
+
+		request = httptest.NewRequest("GET", "/users/42", nil)
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Body.String()).To(Equal("/users/:id"))
+	})
+
+	t.Run("It does not change the routes of a previous table", func(t *testing.T) {
+		router := New()
+		router.HandleFunc("GET", "/users/:id", patternHandler)
+		previous := router.table.Load()
+
+		router.HandleFunc("GET", "/users/:id/posts", patternHandler)
+		router.HandleFunc("GET", "/users/:id<int>", patternHandler)
+
+		request := httptest.NewRequest("GET", "/users/42/posts", nil)
+		handler, _ := router.handler(previous.routes, Params{}, request)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		g.Expect(recorder.Code).To(Equal(http.StatusNotFound))
+
+		request = httptest.NewRequest("GET", "/users/42", nil)
+		handler, request = router.handler(previous.routes, Params{}, request)
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		g.Expect(recorder.Body.String()).To(Equal("/users/:id"))
+	})
+}
@@ -0,0 +1,82 @@
+package urlrouter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrUnknownRoute is returned when building a url for a name that is not registered
+var ErrUnknownRoute = errors.New("no route is registered with the name")
+
+// URL builds the path for a route registered with WithName. The params are pairs of
+// named parameter names and values, such as URL("user", "id", "42"). Values are escaped,
+// and must pass any constraint on the named parameter. A catch all parameter can contain
+// '/', but a named parameter cannot. Every named parameter of the route must be provided
+// exactly once, and no others.
+func (router *Router) URL(name string, params ...string) (string, error) {
+	namedEndpoint, ok := router.table.Load().names[name]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownRoute, name)
+	}
+
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("route %q requires pairs of named parameters and values, received %d strings", namedEndpoint.pattern, len(params))
+	}
+
+	values := map[string]string{}
+	for index := 0; index < len(params); index += 2 {
+		if !contains(namedEndpoint.paramNames, params[index]) {
+			return "", &ParamError{Name: params[index], Pattern: namedEndpoint.pattern, Value: params[index+1], Err: ErrUnknownParam}
+		}
+
+		if _, ok := values[params[index]]; ok {
+			return "", &ParamError{Name: params[index], Pattern: namedEndpoint.pattern, Value: params[index+1], Err: ErrDuplicateParam}
+		}
+
+		values[params[index]] = params[index+1]
+	}
+
+	splitPaths, _ := splitPaths(namedEndpoint.pattern)
+
+	builder := strings.Builder{}
+	for _, path := range splitPaths {
+		switch {
+		case strings.HasPrefix(path, "*"):
+			name := trimPaths(path)
+			value, ok := values[name]
+			if !ok {
+				return "", &ParamError{Name: name, Pattern: namedEndpoint.pattern, Err: ErrMissingParam}
+			}
+
+			segments := strings.Split(value, "/")
+			for index, segment := range segments {
+				segments[index] = url.PathEscape(segment)
+			}
+
+			builder.WriteString(strings.Join(segments, "/"))
+		case strings.HasPrefix(path, ":"):
+			// the pattern was already parsed when the route was registered
+			name, constraint, constraintFunc, _ := parseNamedParameter(path, router.constraints)
+			value, ok := values[name]
+			if !ok {
+				return "", &ParamError{Name: name, Pattern: namedEndpoint.pattern, Err: ErrMissingParam}
+			}
+
+			if value == "" || strings.Contains(value, "/") {
+				return "", &ParamError{Name: name, Pattern: namedEndpoint.pattern, Value: value, Type: "path segment"}
+			}
+
+			if constraintFunc != nil && !constraintFunc(value) {
+				return "", &ParamError{Name: name, Pattern: namedEndpoint.pattern, Value: value, Type: constraint}
+			}
+
+			builder.WriteString(url.PathEscape(value))
+		default:
+			builder.WriteString(path)
+		}
+	}
+
+	return builder.String(), nil
+}
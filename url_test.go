@@ -0,0 +1,93 @@
+package urlrouter
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRouter_URL(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	foundHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	router := New()
+	router.HandleFunc("GET", "/health", foundHandler, WithName("health"))
+	router.HandleFunc("GET", "/users/:id<int>", foundHandler, WithName("user"))
+	router.HandleFunc("DELETE", "/users/:id<int>", foundHandler, WithName("user"))
+	router.HandleFunc("GET", "/users/:id/posts/:post", foundHandler, WithName("post"))
+	router.HandleFunc("GET", "/files/*filepath", foundHandler, WithName("file"))
+	router.HandleFunc("GET", "/:tenant/", foundHandler, WithName("tenant"))
+	router.Group("/v1").HandleFunc("GET", "/teams/:team", foundHandler, WithName("team"))
+
+	t.Run("It panics if the name is used by a different pattern", func(t *testing.T) {
+		g.Expect(func() { router.HandleFunc("GET", "/other", foundHandler, WithName("health")) }).To(Panic())
+	})
+
+	t.Run("It builds urls for named routes", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			params   []string
+			expected string
+		}{
+			{name: "health", expected: "/health"},
+			{name: "user", params: []string{"id", "42"}, expected: "/users/42"},
+			{name: "post", params: []string{"post", "hello world", "id", "bob"}, expected: "/users/bob/posts/hello%20world"},
+			{name: "file", params: []string{"filepath", "css/my site.css"}, expected: "/files/css/my%20site.css"},
+			{name: "file", params: []string{"filepath", ""}, expected: "/files/"},
+			{name: "tenant", params: []string{"tenant", "acme"}, expected: "/acme/"},
+			{name: "team", params: []string{"team", "a?b"}, expected: "/v1/teams/a%3Fb"},
+		}
+
+		for _, test := range tests {
+			url, err := router.URL(test.name, test.params...)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(url).To(Equal(test.expected))
+		}
+	})
+
+	t.Run("It returns an error for an unknown name", func(t *testing.T) {
+		_, err := router.URL("unknown")
+		g.Expect(errors.Is(err, ErrUnknownRoute)).To(BeTrue())
+	})
+
+	t.Run("It returns an error for missing parameters", func(t *testing.T) {
+		_, err := router.URL("post", "id", "42")
+		g.Expect(err).To(MatchError(`named parameter "post" of route "/users/:id/posts/:post" is not set`))
+		g.Expect(errors.Is(err, ErrMissingParam)).To(BeTrue())
+	})
+
+	t.Run("It returns an error for extra parameters", func(t *testing.T) {
+		_, err := router.URL("health", "id", "42")
+		g.Expect(err).To(MatchError(`named parameter "id" is not used by route "/health"`))
+		g.Expect(errors.Is(err, ErrUnknownParam)).To(BeTrue())
+	})
+
+	t.Run("It returns an error for parameters that are set more than once", func(t *testing.T) {
+		_, err := router.URL("post", "id", "1", "post", "hello", "id", "2")
+		g.Expect(err).To(MatchError(`named parameter "id" of route "/users/:id/posts/:post" is set more than once`))
+		g.Expect(errors.Is(err, ErrDuplicateParam)).To(BeTrue())
+	})
+
+	t.Run("It returns an error for unpaired parameters", func(t *testing.T) {
+		_, err := router.URL("user", "id")
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("It returns an error for values that fail the constraint", func(t *testing.T) {
+		_, err := router.URL("user", "id", "bob")
+		g.Expect(err).To(MatchError(`named parameter "id" of route "/users/:id<int>" is not a valid <int>: "bob"`))
+	})
+
+	t.Run("It returns an error for named parameters that would not match a single path", func(t *testing.T) {
+		_, err := router.URL("post", "id", "a/b", "post", "1")
+		g.Expect(err).To(MatchError(`named parameter "id" of route "/users/:id/posts/:post" is not a valid path segment: "a/b"`))
+
+		_, err = router.URL("post", "id", "", "post", "1")
+		g.Expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,429 @@
+package urlrouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMaxBodySize is the largest body read when validating a request, unless the router
+// was created WithMaxBodySize
+const defaultMaxBodySize = 1 << 20
+
+// WithValidation validates the requests of every route described by WithOperation before
+// calling the route's handler. The named parameters, the query parameters and the JSON body
+// are checked against the operation's schemas, and any problems are answered with a 400
+// response encoding ValidationErrors as JSON. Validation runs after the route's middleware
+func WithValidation() Option {
+	return func(router *Router) {
+		router.validation = true
+	}
+}
+
+// WithMaxBodySize limits the size of the bodies read when validating requests, in bytes.
+// Larger bodies are reported as invalid. Defaults to 1MB
+func WithMaxBodySize(size int64) Option {
+	return func(router *Router) {
+		router.maxBodySize = size
+	}
+}
+
+// ValidationError describes a part of a request that does not match the route's schemas
+type ValidationError struct {
+	In      string `json:"in"`             // where the value is, one of "path", "query" or "body"
+	Name    string `json:"name,omitempty"` // name of the parameter, or location in the body such as "items[0].name"
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%s %s", e.In, e.Message)
+	}
+
+	return fmt.Sprintf("%s %q %s", e.In, e.Name, e.Message)
+}
+
+// ValidationErrors are all of the problems found when validating a request
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Sprintf("request is invalid: %s", strings.Join(messages, "; "))
+}
+
+// validator checks requests against the schemas of an operation. The body's schema is
+// described once, when the route is registered
+type validator struct {
+	operation   *Operation
+	request     *Schema
+	maxBodySize int64
+}
+
+// validate wraps a handler so requests are only passed on when they match the operation.
+// Bodies larger than the max body size are not read
+func validate(operation *Operation, maxBodySize int64, handler http.Handler) http.Handler {
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+
+	requestValidator := &validator{operation: operation, maxBodySize: maxBodySize}
+	if operation.Request != nil {
+		requestValidator.request = bodySchema(operation.Request)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if errs := requestValidator.validate(w, r); len(errs) != 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(struct {
+				Errors ValidationErrors `json:"errors"`
+			}{Errors: errs})
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// validate returns every problem with the request's named parameters, query parameters and
+// body. When the body is read, it is replaced so the handler can read it again
+func (v *validator) validate(w http.ResponseWriter, r *http.Request) ValidationErrors {
+	var errs ValidationErrors
+
+	params := GetParams(r.Context())
+	for index, name := range params.names {
+		if schema := v.operation.Params[name]; schema != nil {
+			errs = append(errs, schema.validateParameter("path", name, params.values[index:index+1])...)
+		}
+	}
+
+	query := r.URL.Query()
+	for _, parameter := range v.operation.Query {
+		values, ok := query[parameter.Name]
+		switch {
+		case !ok && parameter.Required:
+			errs = append(errs, &ValidationError{In: "query", Name: parameter.Name, Message: "is required"})
+		case ok && parameter.Schema != nil:
+			errs = append(errs, parameter.Schema.validateParameter("query", parameter.Name, values)...)
+		}
+	}
+
+	if v.request != nil {
+		errs = append(errs, v.validateBody(w, r)...)
+	}
+
+	return errs
+}
+
+func (v *validator) validateBody(w http.ResponseWriter, r *http.Request) ValidationErrors {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+			return ValidationErrors{{In: "body", Message: fmt.Sprintf("must be JSON, not %q", contentType)}}
+		}
+	}
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, v.maxBodySize)); err != nil {
+			if _, ok := err.(*http.MaxBytesError); ok {
+				return ValidationErrors{{In: "body", Message: fmt.Sprintf("must be at most %d bytes", v.maxBodySize)}}
+			}
+
+			return ValidationErrors{{In: "body", Message: fmt.Sprintf("could not be read: %s", err)}}
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if v.operation.RequestRequired {
+			return ValidationErrors{{In: "body", Message: "is required"}}
+		}
+
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return ValidationErrors{{In: "body", Message: "is not valid JSON"}}
+	}
+
+	var errs ValidationErrors
+	for _, err := range v.request.validate("", value) {
+		err.In = "body"
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// validateParameter checks the string values of a path or query parameter. An array schema
+// checks every value of the parameter, otherwise only the first value is checked
+func (schema *Schema) validateParameter(in, name string, values []string) ValidationErrors {
+	var value any
+	var err error
+
+	if schema.Type == "array" {
+		items := &Schema{}
+		if schema.Items != nil {
+			items = schema.Items
+		}
+
+		array := make([]any, len(values))
+		for index, item := range values {
+			if array[index], err = items.parse(item); err != nil {
+				return ValidationErrors{{In: in, Name: fmt.Sprintf("%s[%d]", name, index), Message: err.Error()}}
+			}
+		}
+		value = array
+	} else if value, err = schema.parse(values[0]); err != nil {
+		return ValidationErrors{{In: in, Name: name, Message: err.Error()}}
+	}
+
+	var errs ValidationErrors
+	for _, err := range schema.validate(name, value) {
+		err.In = in
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// parse converts a parameter's value into the same type it would have when decoded from JSON
+func (schema *Schema) parse(value string) (any, error) {
+	switch schema.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return json.Number(value), nil
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return json.Number(value), nil
+	case "boolean":
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return boolean, nil
+	default:
+		return value, nil
+	}
+}
+
+// validate checks a value decoded from JSON against the schema. The name is the location of
+// the value, which is used to name the problems found in nested values
+func (schema *Schema) validate(name string, value any) ValidationErrors {
+	invalid := func(format string, args ...any) ValidationErrors {
+		return ValidationErrors{{Name: name, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if len(schema.Enum) != 0 && !inEnum(schema.Enum, value) {
+		return invalid("must be one of %v", schema.Enum)
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return invalid("must be an object")
+		}
+
+		return schema.validateObject(name, object)
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return invalid("must be an array")
+		}
+
+		var errs ValidationErrors
+		if schema.Items != nil {
+			for index, item := range array {
+				errs = append(errs, schema.Items.validate(fmt.Sprintf("%s[%d]", name, index), item)...)
+			}
+		}
+		return errs
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return invalid("must be a string")
+		}
+
+		if message := schema.validateString(text); message != "" {
+			return invalid("%s", message)
+		}
+		return nil
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok && schema.Type == "integer" {
+			return invalid("must be an integer")
+		} else if !ok {
+			return invalid("must be a number")
+		}
+
+		if message := schema.validateNumber(number); message != "" {
+			return invalid("%s", message)
+		}
+		return nil
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return invalid("must be a boolean")
+		}
+		return nil
+	default:
+		return invalid("has an unsupported schema type %q", schema.Type)
+	}
+}
+
+func (schema *Schema) validateObject(name string, object map[string]any) ValidationErrors {
+	var errs ValidationErrors
+
+	for _, required := range schema.Required {
+		if _, ok := object[required]; !ok {
+			errs = append(errs, &ValidationError{Name: joinName(name, required), Message: "is required"})
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if property, ok := schema.Properties[key]; ok {
+			errs = append(errs, property.validate(joinName(name, key), object[key])...)
+		} else if schema.AdditionalProperties != nil {
+			errs = append(errs, schema.AdditionalProperties.validate(joinName(name, key), object[key])...)
+		}
+	}
+
+	return errs
+}
+
+func (schema *Schema) validateString(text string) string {
+	length := len([]rune(text))
+
+	switch {
+	case schema.MinLength != nil && length < *schema.MinLength:
+		return fmt.Sprintf("must be at least %d characters", *schema.MinLength)
+	case schema.MaxLength != nil && length > *schema.MaxLength:
+		return fmt.Sprintf("must be at most %d characters", *schema.MaxLength)
+	}
+
+	if schema.Pattern != "" {
+		pattern, err := compilePattern(schema.Pattern)
+		if err != nil {
+			return fmt.Sprintf("has an invalid pattern %q in its schema", schema.Pattern)
+		}
+
+		if !pattern.MatchString(text) {
+			return fmt.Sprintf("must match the pattern %q", schema.Pattern)
+		}
+	}
+
+	switch schema.Format {
+	case "uuid":
+		if !defaultConstraints["uuid"](text) {
+			return "must be a uuid"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, text); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", text); err != nil {
+			return "must be a date formatted as 2006-01-02"
+		}
+	}
+
+	return ""
+}
+
+func (schema *Schema) validateNumber(number json.Number) string {
+	value, err := number.Float64()
+	if err != nil {
+		return "must be a number"
+	}
+
+	if schema.Type == "integer" {
+		integer, err := number.Int64()
+		if err != nil {
+			return "must be an integer"
+		}
+
+		if schema.Format == "int32" && (integer < math.MinInt32 || integer > math.MaxInt32) {
+			return "must be a 32 bit integer"
+		}
+	}
+
+	switch {
+	case schema.Minimum != nil && value < *schema.Minimum:
+		return fmt.Sprintf("must be at least %v", *schema.Minimum)
+	case schema.Maximum != nil && value > *schema.Maximum:
+		return fmt.Sprintf("must be at most %v", *schema.Maximum)
+	}
+
+	return ""
+}
+
+// inEnum reports if the value is one of the enum's values. Values are compared by their
+// text, since the enum might have been decoded from YAML with different types
+func inEnum(enum []any, value any) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// joinName names a property of an object in the body
+func joinName(name, property string) string {
+	if name == "" {
+		return property
+	}
+
+	return name + "." + property
+}
+
+// patterns holds the compiled patterns of schemas, since a schema is validated on every request
+var patterns sync.Map
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if compiled, ok := patterns.Load(pattern); ok {
+		return compiled.(*regexp.Regexp), nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns.Store(pattern, compiled)
+	return compiled, nil
+}
@@ -0,0 +1,216 @@
+package urlrouter
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRouter_Validation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type item struct {
+		Name     string `json:"name"`
+		Quantity int    `json:"quantity"`
+	}
+
+	type createOrder struct {
+		Customer string   `json:"customer"`
+		Items    []item   `json:"items"`
+		Notes    string   `json:"notes,omitempty"`
+		Express  bool     `json:"express,omitempty"`
+		Tags     []string `json:"tags,omitempty"`
+	}
+
+	one, hundred := float64(1), float64(100)
+
+	echoHandler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}
+
+	setupRouter := func(options ...Option) *Router {
+		router := New(options...)
+		router.HandleFunc("POST", "/stores/:store/orders", echoHandler, WithOperation(Operation{
+			Params: map[string]*Schema{"store": {Type: "string", Format: "uuid"}},
+			Query: []*OpenAPIParameter{
+				{Name: "limit", Required: true, Schema: &Schema{Type: "integer", Minimum: &one, Maximum: &hundred}},
+				{Name: "sort", Schema: &Schema{Type: "string", Enum: []any{"asc", "desc"}}},
+				{Name: "ids", Schema: &Schema{Type: "array", Items: &Schema{Type: "integer"}}},
+			},
+			Request:         createOrder{},
+			RequestRequired: true,
+		}))
+		router.HandleFunc("GET", "/stores/:store", echoHandler, WithOperation(Operation{
+			Params: map[string]*Schema{"store": {Type: "integer", Format: "int32"}},
+		}))
+		router.HandleFunc("GET", "/health", echoHandler)
+		return router
+	}
+
+	serve := func(router *Router, method, target, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	validationErrors := func(recorder *httptest.ResponseRecorder) ValidationErrors {
+		g.Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		g.Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		response := struct {
+			Errors ValidationErrors `json:"errors"`
+		}{}
+		g.Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+		return response.Errors
+	}
+
+	validOrder := `{"customer": "bob", "items": [{"name": "apple", "quantity": 2}]}`
+	storeID := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	t.Run("It passes valid requests to the handler with the body", func(t *testing.T) {
+		router := setupRouter(WithValidation())
+
+		recorder := serve(router, "POST", "/stores/"+storeID+"/orders?limit=10&sort=asc&ids=1&ids=2", validOrder)
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Body.String()).To(Equal(validOrder))
+
+		g.Expect(serve(router, "GET", "/health", "").Code).To(Equal(http.StatusOK))
+		g.Expect(serve(router, "GET", "/stores/42", "").Code).To(Equal(http.StatusOK))
+	})
+
+	t.Run("It does not validate without the option", func(t *testing.T) {
+		router := setupRouter()
+		g.Expect(serve(router, "POST", "/stores/abc/orders", "nope").Code).To(Equal(http.StatusOK))
+	})
+
+	t.Run("It reports invalid named parameters", func(t *testing.T) {
+		router := setupRouter(WithValidation())
+
+		g.Expect(validationErrors(serve(router, "GET", "/stores/abc", ""))).To(Equal(ValidationErrors{
+			{In: "path", Name: "store", Message: "must be an integer"},
+		}))
+		g.Expect(validationErrors(serve(router, "GET", "/stores/9999999999", ""))).To(Equal(ValidationErrors{
+			{In: "path", Name: "store", Message: "must be a 32 bit integer"},
+		}))
+	})
+
+	t.Run("It reports every invalid part of a request", func(t *testing.T) {
+		router := setupRouter(WithValidation())
+
+		recorder := serve(router, "POST", "/stores/abc/orders?sort=up&ids=1&ids=x", `{"items": [{"name": 3, "quantity": 1.5}, {}], "express": "yes", "extra": true}`)
+		g.Expect(validationErrors(recorder)).To(Equal(ValidationErrors{
+			{In: "path", Name: "store", Message: "must be a uuid"},
+			{In: "query", Name: "limit", Message: "is required"},
+			{In: "query", Name: "sort", Message: "must be one of [asc desc]"},
+			{In: "query", Name: "ids[1]", Message: "must be an integer"},
+			{In: "body", Name: "customer", Message: "is required"},
+			{In: "body", Name: "express", Message: "must be a boolean"},
+			{In: "body", Name: "items[0].name", Message: "must be a string"},
+			{In: "body", Name: "items[0].quantity", Message: "must be an integer"},
+			{In: "body", Name: "items[1].name", Message: "is required"},
+			{In: "body", Name: "items[1].quantity", Message: "is required"},
+		}))
+	})
+
+	t.Run("It checks the limits of numbers", func(t *testing.T) {
+		router := setupRouter(WithValidation())
+
+		recorder := serve(router, "POST", "/stores/"+storeID+"/orders?limit=0", validOrder)
+		g.Expect(validationErrors(recorder)).To(Equal(ValidationErrors{{In: "query", Name: "limit", Message: "must be at least 1"}}))
+
+		recorder = serve(router, "POST", "/stores/"+storeID+"/orders?limit=101", validOrder)
+		g.Expect(validationErrors(recorder)).To(Equal(ValidationErrors{{In: "query", Name: "limit", Message: "must be at most 100"}}))
+	})
+
+	t.Run("It reports missing, malformed and non JSON bodies", func(t *testing.T) {
+		router := setupRouter(WithValidation())
+		target := "/stores/" + storeID + "/orders?limit=1"
+
+		g.Expect(validationErrors(serve(router, "POST", target, ""))).To(Equal(ValidationErrors{{In: "body", Message: "is required"}}))
+		g.Expect(validationErrors(serve(router, "POST", target, `{"customer": `))).To(Equal(ValidationErrors{{In: "body", Message: "is not valid JSON"}}))
+		g.Expect(validationErrors(serve(router, "POST", target, `[]`))).To(Equal(ValidationErrors{{In: "body", Message: "must be an object"}}))
+
+		request := httptest.NewRequest("POST", target, strings.NewReader(validOrder))
+		request.Header.Set("Content-Type", "text/plain")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		g.Expect(validationErrors(recorder)).To(Equal(ValidationErrors{{In: "body", Message: `must be JSON, not "text/plain"`}}))
+
+		request = httptest.NewRequest("POST", target, strings.NewReader(validOrder))
+		request.Header.Set("Content-Type", "application/json; charset=utf-8")
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	t.Run("It accepts an empty body when the body is not required", func(t *testing.T) {
+		router := New(WithValidation())
+		router.HandleFunc("PATCH", "/stores/:store", echoHandler, WithOperation(Operation{Request: createOrder{}}))
+
+		g.Expect(serve(router, "PATCH", "/stores/"+storeID, "").Code).To(Equal(http.StatusOK))
+		g.Expect(validationErrors(serve(router, "PATCH", "/stores/"+storeID, `[]`))).To(Equal(ValidationErrors{{In: "body", Message: "must be an object"}}))
+	})
+
+	t.Run("It limits the size of the body that is read", func(t *testing.T) {
+		router := setupRouter(WithValidation(), WithMaxBodySize(16))
+		target := "/stores/" + storeID + "/orders?limit=1"
+
+		g.Expect(validationErrors(serve(router, "POST", target, validOrder))).To(Equal(ValidationErrors{{In: "body", Message: "must be at most 16 bytes"}}))
+		g.Expect(validationErrors(serve(router, "POST", target, `{"items": []}`))).To(Equal(ValidationErrors{{In: "body", Name: "customer", Message: "is required"}}))
+	})
+
+	t.Run("It runs after the route's middleware", func(t *testing.T) {
+		router := New(WithValidation())
+		router.HandleFunc("GET", "/stores/:store", echoHandler,
+			WithMiddleware(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("X-Middleware", "route")
+					next.ServeHTTP(w, r)
+				})
+			}),
+			WithOperation(Operation{Params: map[string]*Schema{"store": {Type: "integer"}}}),
+		)
+
+		recorder := serve(router, "GET", "/stores/abc", "")
+		g.Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		g.Expect(recorder.Header().Get("X-Middleware")).To(Equal("route"))
+	})
+
+	t.Run("It validates routes loaded from an OpenAPI document", func(t *testing.T) {
+		router := New(WithValidation())
+		g.Expect(router.LoadOpenAPI([]byte(`
+openapi: 3.0.3
+info: {title: t, version: "1"}
+paths:
+  /users/{id}:
+    put:
+      operationId: updateUser
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string, pattern: "^[a-z]+$", maxLength: 5}}
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                role: {type: string, enum: [admin, member]}
+`), map[string]http.HandlerFunc{"updateUser": echoHandler})).To(Succeed())
+
+		g.Expect(serve(router, "PUT", "/users/bob", `{"role": "admin"}`).Code).To(Equal(http.StatusOK))
+		g.Expect(serve(router, "PUT", "/users/bob", "").Code).To(Equal(http.StatusOK))
+		g.Expect(validationErrors(serve(router, "PUT", "/users/Bob42", `{"role": "owner"}`))).To(Equal(ValidationErrors{
+			{In: "path", Name: "id", Message: `must match the pattern "^[a-z]+$"`},
+			{In: "body", Name: "role", Message: "must be one of [admin member]"},
+		}))
+		g.Expect(validationErrors(serve(router, "PUT", "/users/robert", `{}`))).To(Equal(ValidationErrors{
+			{In: "path", Name: "id", Message: "must be at most 5 characters"},
+		}))
+	})
+}
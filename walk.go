@@ -0,0 +1,103 @@
+package urlrouter
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Route describes a url handler registered on a Router
+type Route struct {
+	Method  string       // method the route was registered for
+	Host    string       // host pattern the route was registered for, empty for the default host
+	Pattern string       // pattern the route was registered with
+	Name    string       // name set with WithName, if any
+	Handler http.Handler // handler of the route, including any route and group middleware
+}
+
+// WalkFunc is called for every route registered on a Router. Returning an error stops the walk
+type WalkFunc func(method, pattern string, handler http.Handler) error
+
+// Routes returns every route registered on the router, in the same order as Walk
+func (router *Router) Routes() []Route {
+	var registered []Route
+
+	_ = router.table.Load().walk(func(foundEndpoint *endpoint) error {
+		registered = append(registered, Route{
+			Method:  foundEndpoint.method,
+			Host:    foundEndpoint.host,
+			Pattern: foundEndpoint.pattern,
+			Name:    foundEndpoint.name,
+			Handler: foundEndpoint.handlerFunc,
+		})
+		return nil
+	})
+
+	return registered
+}
+
+// Walk calls walkFunc for every route registered on the router, returning the first error
+// returned by walkFunc. The routes without a host are walked first, followed by the routes
+// of each host in the order they are matched. The methods are walked in sorted order.
+// Beneath each method, a route's handler comes before the routes beneath it, which are
+// walked in the order of url paths sorted, named parameters, catch all parameters and then
+// wildcards. Routes registered while walking are not included
+func (router *Router) Walk(walkFunc WalkFunc) error {
+	return router.table.Load().walk(func(foundEndpoint *endpoint) error {
+		return walkFunc(foundEndpoint.method, foundEndpoint.pattern, foundEndpoint.handlerFunc)
+	})
+}
+
+// walk calls walkFunc for every endpoint in the table, see Router.Walk for the order
+func (t *table) walk(walkFunc func(foundEndpoint *endpoint) error) error {
+	for _, tree := range t.trees() {
+		methods := make([]string, 0, len(tree))
+		for method := range tree {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			if err := tree[method].walk(walkFunc); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *route) walk(walkFunc func(foundEndpoint *endpoint) error) error {
+	for _, foundEndpoint := range r.handler {
+		if err := walkFunc(foundEndpoint); err != nil {
+			return err
+		}
+	}
+
+	paths := make([]string, 0, len(r.urlChildren))
+	for path := range r.urlChildren {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := r.urlChildren[path].walk(walkFunc); err != nil {
+			return err
+		}
+	}
+
+	for _, namedChild := range r.namedChildren {
+		if err := namedChild.walk(walkFunc); err != nil {
+			return err
+		}
+	}
+
+	for _, foundEndpoints := range []candidates{r.catchAll, r.wildcard} {
+		for _, foundEndpoint := range foundEndpoints {
+			if err := walkFunc(foundEndpoint); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package urlrouter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRouter_Walk(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	foundHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	setupRouter := func() *Router {
+		router := New()
+		router.HandleFunc("POST", "/users", foundHandler)
+		router.HandleFunc("GET", "/users/", foundHandler)
+		router.HandleFunc("GET", "/users/*rest", foundHandler)
+		router.HandleFunc("GET", "/users/:id", foundHandler, WithName("user"))
+		router.HandleFunc("GET", "/users/:id<int>", foundHandler)
+		router.HandleFunc("GET", "/users/me", foundHandler)
+		router.HandleFunc("GET", "/users", foundHandler)
+		router.HandleFunc("GET", "/about", foundHandler)
+		router.HandleFunc("DELETE", "/users/:id", foundHandler, WithName("user"))
+		return router
+	}
+
+	t.Run("It walks every route in a deterministic order", func(t *testing.T) {
+		router := setupRouter()
+
+		var walked []string
+		err := router.Walk(func(method, pattern string, handler http.Handler) error {
+			g.Expect(handler).ToNot(BeNil())
+			walked = append(walked, method+" "+pattern)
+			return nil
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(walked).To(Equal([]string{
+			"DELETE /users/:id",
+			"GET /about",
+			"GET /users",
+			"GET /users/me",
+			"GET /users/:id<int>",
+			"GET /users/:id",
+			"GET /users/*rest",
+			"GET /users/",
+			"POST /users",
+		}))
+	})
+
+	t.Run("It stops at the first error", func(t *testing.T) {
+		router := setupRouter()
+		stop := errors.New("stop")
+
+		count := 0
+		err := router.Walk(func(method, pattern string, handler http.Handler) error {
+			count++
+			if pattern == "/users" {
+				return stop
+			}
+			return nil
+		})
+
+		g.Expect(err).To(Equal(stop))
+		g.Expect(count).To(Equal(3))
+	})
+
+	t.Run("It returns nothing for an empty router", func(t *testing.T) {
+		g.Expect(New().Routes()).To(BeEmpty())
+	})
+
+	t.Run("It lists the routes with their names and handlers", func(t *testing.T) {
+		router := setupRouter()
+		router.HandleFunc("PUT", "/users/:id", foundHandler, WithMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Middleware", "route")
+				next.ServeHTTP(w, r)
+			})
+		}))
+
+		routes := router.Routes()
+		g.Expect(routes).To(HaveLen(10))
+		g.Expect(routes[0].Method).To(Equal("DELETE"))
+		g.Expect(routes[0].Pattern).To(Equal("/users/:id"))
+		g.Expect(routes[0].Name).To(Equal("user"))
+		g.Expect(routes[9].Method).To(Equal("PUT"))
+
+		recorder := httptest.NewRecorder()
+		routes[9].Handler.ServeHTTP(recorder, httptest.NewRequest("PUT", "/users/42", nil))
+		g.Expect(recorder.Code).To(Equal(http.StatusOK))
+		g.Expect(recorder.Header().Get("X-Middleware")).To(Equal("route"))
+	})
+}